@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/adapter/metrics"
+)
+
+func TestRoute_CountsEveryRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	videoMetrics := metrics.NewPrometheusMetrics()
+	// Only requests carrying a token get past authentication.
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	unlimited := func(pattern string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	route := newRoute(mux, authenticate, unlimited, videoMetrics.InstrumentHandler)
+	route("/usage", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, token := range []string{"Bearer token", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/usage", nil)
+		req.Header.Set("Authorization", token)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	videoMetrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		`http_requests_total{code="200",method="get",route="/usage"} 1`,
+		`http_requests_total{code="401",method="get",route="/usage"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), line) {
+			t.Errorf("expected metrics to contain %q", line)
+		}
+	}
+}
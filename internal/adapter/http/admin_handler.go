@@ -0,0 +1,185 @@
+package http_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+// AdminHandler serves the support endpoints under /admin. Every route
+// requires the admin role.
+type AdminHandler struct {
+	Service port.AdminService
+	Logger  *slog.Logger
+}
+
+func (h *AdminHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
+}
+
+type forceStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (h *AdminHandler) ListVideos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actorID, ok := authorizeAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	limit, ok := queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	videos, err := h.Service.ListVideos(r.Context(), actorID, entity.VideoFilter{
+		OwnerId: query.Get("owner_id"),
+		Status:  query.Get("status"),
+		Limit:   limit,
+	})
+	if err != nil {
+		http.Error(w, "Error retrieving videos", http.StatusInternalServerError)
+		h.logger().ErrorContext(r.Context(), "Error retrieving videos", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, videos)
+}
+
+func (h *AdminHandler) ForceStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actorID, ok := authorizeAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var body forceStatusRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.Status == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	video, err := h.Service.ForceStatus(r.Context(), actorID, r.PathValue("id"), body.Status, body.Reason)
+	switch {
+	case errors.Is(err, entity.ErrVideoNotFound):
+		http.Error(w, "Video not found", http.StatusNotFound)
+	case errors.Is(err, entity.ErrInvalidParameters):
+		http.Error(w, "Status can't be forced", http.StatusBadRequest)
+	case errors.Is(err, entity.ErrStatusConflict):
+		http.Error(w, "Video status changed, retry", http.StatusConflict)
+	case err != nil:
+		http.Error(w, "Error updating video status", http.StatusInternalServerError)
+		h.logger().ErrorContext(r.Context(), "Error forcing video status", "error", err)
+	default:
+		writeJSON(w, http.StatusOK, video)
+	}
+}
+
+func (h *AdminHandler) ReprocessVideo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actorID, ok := authorizeAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var body reprocessRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	video, err := h.Service.Reprocess(r.Context(), actorID, r.PathValue("id"), body.IntervalSeconds)
+	writeReprocessResult(r.Context(), w, h.logger(), video, err)
+}
+
+func (h *AdminHandler) PurgeVideo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actorID, ok := authorizeAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.Service.Purge(r.Context(), actorID, r.PathValue("id"))
+	if errors.Is(err, entity.ErrVideoNotFound) {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error purging video", http.StatusInternalServerError)
+		h.logger().ErrorContext(r.Context(), "Error purging video", "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := authorizeAdmin(w, r); !ok {
+		return
+	}
+
+	limit, ok := queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	entries, err := h.Service.GetAuditLog(r.Context(), limit)
+	if err != nil {
+		http.Error(w, "Error retrieving audit log", http.StatusInternalServerError)
+		h.logger().ErrorContext(r.Context(), "Error retrieving audit log", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// queryInt parses an optional integer query parameter, writing a 400 when
+// it is malformed.
+func queryInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		http.Error(w, "Invalid "+name+" query parameter", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return parsed, true
+}
@@ -0,0 +1,116 @@
+package http_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+type APIKeyHandler struct {
+	Service port.APIKeyService
+	Logger  *slog.Logger
+}
+
+func (h *APIKeyHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h *APIKeyHandler) APIKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.CreateAPIKey(w, r)
+	case http.MethodGet:
+		h.GetAPIKeys(w, r)
+	default:
+		http.Error(w, "Only GET and POST methods are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeUser(w, r)
+	if !ok {
+		return
+	}
+
+	var body createAPIKeyRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.Service.Create(ctx, ownerID, body.Name, body.Scopes, body.ExpiresAt)
+	if errors.Is(err, entity.ErrInvalidParameters) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error creating API key", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error creating API key", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeUser(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.Service.GetAPIKeys(ctx, ownerID)
+	if err != nil {
+		http.Error(w, "Error retrieving API keys", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error retrieving API keys", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeUser(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.Service.Revoke(ctx, r.PathValue("id"), ownerID)
+	if errors.Is(err, entity.ErrAPIKeyNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error revoking API key", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error revoking API key", "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
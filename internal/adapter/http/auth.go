@@ -0,0 +1,209 @@
+package http_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+type principalKey struct{}
+
+func withPrincipal(ctx context.Context, principal *entity.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func principalFrom(ctx context.Context) (*entity.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*entity.Principal)
+	return principal, ok
+}
+
+type auditKey struct{}
+
+func withAudit(ctx context.Context, audit port.AuditRepository) context.Context {
+	return context.WithValue(ctx, auditKey{}, audit)
+}
+
+func auditFrom(ctx context.Context) (port.AuditRepository, bool) {
+	audit, ok := ctx.Value(auditKey{}).(port.AuditRepository)
+	return audit, ok && audit != nil
+}
+
+const apiKeyHeader = "X-API-Key"
+
+// Authenticate resolves the caller once and stores it in the request
+// context, rejecting requests without valid credentials before they reach
+// the handler. API keys, sent in X-API-Key or as a bearer token, are checked
+// against apiKeys; anything else is a user token for users. Admins acting on
+// another owner's data are recorded in audit.
+func Authenticate(users port.UserPort, apiKeys port.APIKeyService, audit port.AuditRepository, logger *slog.Logger) func(http.Handler) http.Handler {
+	logger = loggerOrDefault(logger)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			var principal *entity.Principal
+			var err error
+			if key := apiKeyFrom(r); key != "" && apiKeys != nil {
+				principal, err = apiKeys.Authenticate(ctx, key)
+			} else {
+				principal, err = users.Authenticate(ctx, r.Header.Get("Authorization"))
+			}
+			if errors.Is(err, entity.ErrUnauthorized) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+			if errors.Is(err, entity.ErrAuthUnavailable) {
+				w.Header().Set("Retry-After", "5")
+				http.Error(w, entity.ErrAuthUnavailable.Error(), http.StatusServiceUnavailable)
+				logger.WarnContext(ctx, "Error validating token", "error", err)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Error validating token", http.StatusInternalServerError)
+				logger.ErrorContext(ctx, "Error validating token", "error", err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withAudit(withPrincipal(ctx, principal), audit)))
+		})
+	}
+}
+
+func apiKeyFrom(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	token := r.Header.Get("Authorization")
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") && entity.IsAPIKey(token[7:]) {
+		return token[7:]
+	}
+
+	return ""
+}
+
+// authorizeOwner returns the owner the request acts for: the authenticated
+// caller, or the owner_id query parameter when an admin acts on someone
+// else's behalf, which is refused unless it can be recorded in the audit log.
+// API keys must also carry scope. It writes the error response when the
+// caller isn't allowed. The returned context tags log lines with the owner.
+func authorizeOwner(w http.ResponseWriter, r *http.Request, scope string) (context.Context, string, bool) {
+	ctx := r.Context()
+	principal, ok := principalFrom(ctx)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return ctx, "", false
+	}
+	if !principal.HasScope(scope) {
+		http.Error(w, fmt.Sprintf("API key lacks the %s scope", scope), http.StatusForbidden)
+		return ctx, "", false
+	}
+
+	ownerID := principal.OwnerId
+	if override := r.URL.Query().Get("owner_id"); override != "" && override != ownerID {
+		if !principal.IsAdmin() {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return ctx, "", false
+		}
+		ownerID = override
+		audit, ok := auditFrom(ctx)
+		if !ok {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return ctx, "", false
+		}
+		err := audit.Record(ctx, entity.NewAuditEntry(principal.OwnerId, entity.AuditActAsOwner, ownerID, map[string]string{
+			"route":  r.URL.Path,
+			"method": r.Method,
+		}))
+		if err != nil {
+			http.Error(w, "Error recording audit entry", http.StatusInternalServerError)
+			slog.ErrorContext(ctx, "Error recording audit entry", "action", entity.AuditActAsOwner, "error", err)
+			return ctx, "", false
+		}
+		slog.InfoContext(ctx, "Admin acting on behalf of owner", "actor_id", principal.OwnerId, logging.OwnerID, ownerID)
+	}
+	ctx = logging.With(ctx, slog.String(logging.OwnerID, ownerID))
+
+	return ctx, ownerID, true
+}
+
+// authorizeUser is authorizeOwner for endpoints only user tokens may call,
+// such as managing API keys, so a leaked key can't mint more keys. Admins
+// can't act for other owners here either: keys they minted would
+// authenticate as that owner.
+func authorizeUser(w http.ResponseWriter, r *http.Request) (context.Context, string, bool) {
+	principal, ok := principalFrom(r.Context())
+	if ok && principal.APIKeyId != "" {
+		http.Error(w, "API keys can't be used here", http.StatusForbidden)
+		return r.Context(), "", false
+	}
+	if override := r.URL.Query().Get("owner_id"); ok && override != "" && override != principal.OwnerId {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return r.Context(), "", false
+	}
+
+	return authorizeOwner(w, r, "")
+}
+
+// authorizePathOwner returns the caller and the owner named by the id path
+// value, for endpoints reaching all of an owner's data: only the owner, with
+// a user token, or an admin may call them. The returned context tags log
+// lines with the owner.
+func authorizePathOwner(w http.ResponseWriter, r *http.Request) (context.Context, string, string, bool) {
+	ctx := r.Context()
+	principal, ok := principalFrom(ctx)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return ctx, "", "", false
+	}
+	if principal.APIKeyId != "" {
+		http.Error(w, "API keys can't be used here", http.StatusForbidden)
+		return ctx, "", "", false
+	}
+
+	ownerID := r.PathValue("id")
+	if ownerID != principal.OwnerId && !principal.IsAdmin() {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return ctx, "", "", false
+	}
+	ctx = logging.With(ctx, slog.String(logging.OwnerID, ownerID))
+
+	return ctx, principal.OwnerId, ownerID, true
+}
+
+// authorizeAdmin returns the id of the admin making the request, writing the
+// error response for anyone else.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) (string, bool) {
+	principal, ok := principalFrom(r.Context())
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return "", false
+	}
+	if !principal.IsAdmin() {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return "", false
+	}
+
+	return principal.OwnerId, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		slog.Error("Error encoding response", "error", err)
+	}
+}
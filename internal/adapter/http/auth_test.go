@@ -0,0 +1,206 @@
+package http_handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockAPIKeyService struct{}
+
+func (m *MockAPIKeyService) Create(ctx context.Context, ownerId string, name string, scopes []string, expiresAt *time.Time) (*entity.APIKeyResponse, error) {
+	return &entity.APIKeyResponse{Id: "key1", OwnerId: ownerId, Name: name, Scopes: scopes, Key: "vk_secret"}, nil
+}
+
+func (m *MockAPIKeyService) GetAPIKeys(ctx context.Context, ownerId string) ([]entity.APIKeyResponse, error) {
+	return []entity.APIKeyResponse{{Id: "key1", OwnerId: ownerId}}, nil
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, id string, ownerId string) error {
+	return nil
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, key string) (*entity.Principal, error) {
+	if key == "vk_reader" {
+		return &entity.Principal{OwnerId: "123", APIKeyId: "key1", Scopes: []string{entity.ScopeRead}}, nil
+	}
+	return nil, entity.ErrUnauthorized
+}
+
+type MockAuditRepository struct {
+	entries []entity.AuditEntry
+	err     error
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, entry entity.AuditEntry) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditRepository) FindRecent(ctx context.Context, limit int) ([]entity.AuditEntry, error) {
+	return m.entries, nil
+}
+
+func TestAuthenticate_ResolvesPrincipal(t *testing.T) {
+	cases := map[string]int{
+		"Bearer user":   http.StatusOK,
+		"Bearer admin":  http.StatusOK,
+		"Bearer forged": http.StatusUnauthorized,
+		"":              http.StatusUnauthorized,
+		"Bearer broken": http.StatusInternalServerError,
+		"Bearer down":   http.StatusServiceUnavailable,
+	}
+	for header, status := range cases {
+		var owner string
+		handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, &MockAuditRepository{}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := principalFrom(r.Context())
+			owner = principal.OwnerId
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/zips", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != status {
+			t.Errorf("header %q: expected status %d, got %d", header, status, w.Result().StatusCode)
+		}
+		if (owner != "") != (status == http.StatusOK) {
+			t.Errorf("header %q: expected the handler to run only when authenticated, got owner %q", header, owner)
+		}
+	}
+}
+
+func TestAuthorizeOwner_OwnerOverride(t *testing.T) {
+	cases := []struct {
+		token  string
+		query  string
+		status int
+		owner  string
+	}{
+		{"Bearer user", "", http.StatusOK, "123"},
+		{"Bearer user", "?owner_id=123", http.StatusOK, "123"},
+		{"Bearer user", "?owner_id=456", http.StatusForbidden, ""},
+		{"Bearer admin", "?owner_id=456", http.StatusOK, "456"},
+	}
+	for _, c := range cases {
+		var owner string
+		handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, &MockAuditRepository{}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, owner, _ = authorizeOwner(w, r, entity.ScopeRead)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/zips"+c.query, nil)
+		req.Header.Set("Authorization", c.token)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != c.status || owner != c.owner {
+			t.Errorf("%s %s: expected %d for owner %q, got %d for %q", c.token, c.query, c.status, c.owner, w.Result().StatusCode, owner)
+		}
+	}
+}
+
+func TestAuthorizeOwner_OverrideIsAudited(t *testing.T) {
+	audit := &MockAuditRepository{}
+	handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, audit, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizeOwner(w, r, entity.ScopeUpload)
+	}))
+	send := func(query string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/videos/video1"+query, nil)
+		req.Header.Set("Authorization", "Bearer admin")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result().StatusCode
+	}
+
+	send("")
+	if len(audit.entries) != 0 {
+		t.Errorf("Expected admins acting for themselves not to be audited, got %+v", audit.entries)
+	}
+
+	send("?owner_id=456")
+	if len(audit.entries) != 1 {
+		t.Fatalf("Expected the override to be audited, got %+v", audit.entries)
+	}
+	entry := audit.entries[0]
+	if entry.Action != entity.AuditActAsOwner || entry.ActorId != "1" || entry.TargetId != "456" ||
+		entry.Details["route"] != "/videos/video1" || entry.Details["method"] != http.MethodDelete {
+		t.Errorf("Expected the actor, owner, route and method to be recorded, got %+v", entry)
+	}
+
+	audit.err = errors.New("database unavailable")
+	if status := send("?owner_id=456"); status != http.StatusInternalServerError {
+		t.Errorf("Expected an override that can't be audited to be refused, got %d", status)
+	}
+}
+
+func TestAPIKeyHandler_RefusesOwnerOverride(t *testing.T) {
+	handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, &MockAuditRepository{}, nil)(http.HandlerFunc((&APIKeyHandler{Service: &MockAPIKeyService{}}).APIKeys))
+
+	req := httptest.NewRequest(http.MethodPost, "/api-keys?owner_id=456", strings.NewReader(`{"name":"ci","scopes":["read"]}`))
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusForbidden {
+		t.Errorf("Expected admins not to mint keys for other owners, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestAuthenticate_APIKeys(t *testing.T) {
+	cases := []struct {
+		header string
+		value  string
+		scope  string
+		status int
+	}{
+		{apiKeyHeader, "vk_reader", entity.ScopeRead, http.StatusOK},
+		{"Authorization", "Bearer vk_reader", entity.ScopeRead, http.StatusOK},
+		{apiKeyHeader, "vk_reader", entity.ScopeUpload, http.StatusForbidden},
+		{apiKeyHeader, "vk_revoked", entity.ScopeRead, http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		var owner string
+		handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, &MockAuditRepository{}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, owner, _ = authorizeOwner(w, r, c.scope)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/zips", nil)
+		req.Header.Set(c.header, c.value)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != c.status || (owner == "123") != (c.status == http.StatusOK) {
+			t.Errorf("%s %s for %s: expected %d, got %d for owner %q", c.header, c.value, c.scope, c.status, w.Result().StatusCode, owner)
+		}
+	}
+}
+
+func TestAPIKeyHandler_RequiresUserToken(t *testing.T) {
+	handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, &MockAuditRepository{}, nil)(http.HandlerFunc((&APIKeyHandler{Service: &MockAPIKeyService{}}).APIKeys))
+
+	for header, status := range map[string]int{"Bearer user": http.StatusOK, "Bearer vk_reader": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodGet, "/api-keys", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", header, status, w.Result().StatusCode)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package http_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+type EventHandler struct {
+	Subscriber port.EventSubscriber
+	Logger     *slog.Logger
+	// Done, once closed, ends the open streams, which would otherwise keep
+	// a graceful shutdown waiting until its deadline. Clients reconnect to
+	// another replica.
+	Done <-chan struct{}
+}
+
+func (h *EventHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
+}
+
+// StreamEvents streams the owner's video events as server-sent events.
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.Subscriber.Subscribe(ownerID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.Done:
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger().ErrorContext(ctx, "Error encoding event", "error", err)
+				continue
+			}
+
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Event, data)
+			flusher.Flush()
+		}
+	}
+}
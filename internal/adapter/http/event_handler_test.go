@@ -0,0 +1,36 @@
+package http_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockEventSubscriber struct{}
+
+func (m *MockEventSubscriber) Subscribe(ownerId string) (<-chan entity.VideoEvent, func()) {
+	return make(chan entity.VideoEvent), func() {}
+}
+
+func TestStreamEvents_EndsOnShutdown(t *testing.T) {
+	done := make(chan struct{})
+	handler := &EventHandler{Subscriber: &MockEventSubscriber{}, Done: done}
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/events", nil))
+	w := httptest.NewRecorder()
+
+	returned := make(chan struct{})
+	go func() {
+		handler.StreamEvents(w, req)
+		close(returned)
+	}()
+	close(done)
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to end on shutdown")
+	}
+}
@@ -0,0 +1,37 @@
+package http_handler
+
+import (
+	"net/http"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+type HealthHandler struct {
+	Service port.HealthService
+}
+
+// Healthz reports whether the process is alive. It never checks dependencies
+// so a database outage does not get every replica restarted.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.Service.Live())
+}
+
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := h.Service.Ready(r.Context())
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, report)
+}
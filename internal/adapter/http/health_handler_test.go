@@ -0,0 +1,60 @@
+package http_handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockHealthService struct {
+	ready bool
+}
+
+func (m *MockHealthService) Live() entity.HealthReport {
+	return entity.HealthReport{Status: entity.HealthStatusUp}
+}
+
+func (m *MockHealthService) Ready(ctx context.Context) entity.HealthReport {
+	if !m.ready {
+		return entity.HealthReport{
+			Status: entity.HealthStatusDown,
+			Checks: map[string]entity.HealthCheckResult{"postgres": {Status: entity.HealthStatusDown, Error: "connection refused"}},
+		}
+	}
+	return entity.HealthReport{Status: entity.HealthStatusUp}
+}
+
+func TestHealthz_Success(t *testing.T) {
+	handler := &HealthHandler{Service: &MockHealthService{}}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	handler.Healthz(w, req)
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+	}
+}
+
+func TestReadyz_StatusCodes(t *testing.T) {
+	cases := map[bool]int{
+		true:  http.StatusOK,
+		false: http.StatusServiceUnavailable,
+	}
+	for ready, expected := range cases {
+		handler := &HealthHandler{Service: &MockHealthService{ready: ready}}
+
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		w := httptest.NewRecorder()
+
+		handler.Readyz(w, req)
+
+		if w.Result().StatusCode != expected {
+			t.Errorf("ready %t: expected status %d, got %d", ready, expected, w.Result().StatusCode)
+		}
+	}
+}
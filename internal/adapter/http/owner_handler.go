@@ -0,0 +1,103 @@
+package http_handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+// OwnerHandler serves the data export and erasure endpoints under
+// /owners/{id}, for the owner themselves or an admin.
+type OwnerHandler struct {
+	Service port.OwnerService
+	Logger  *slog.Logger
+}
+
+func (h *OwnerHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
+}
+
+func (h *OwnerHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, actorID, ownerID, ok := authorizePathOwner(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.Service.Export(ctx, actorID, ownerID)
+	if err != nil {
+		http.Error(w, "Error exporting data", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error exporting data", "error", err)
+		return
+	}
+	defer export.Close()
+
+	w.Header().Set("Content-Disposition", "attachment; filename=export.zip")
+	w.Header().Set("Content-Type", "application/zip")
+
+	// The status is already sent by the time a failure shows up, so the
+	// client is left with a truncated archive.
+	_, err = io.Copy(w, export)
+	if err != nil {
+		h.logger().ErrorContext(ctx, "Error writing export", "error", err)
+	}
+}
+
+// Erase schedules the erasure and answers straight away with the erasure
+// to poll for its receipt.
+func (h *OwnerHandler) Erase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, actorID, ownerID, ok := authorizePathOwner(w, r)
+	if !ok {
+		return
+	}
+
+	erasure, err := h.Service.Erase(ctx, actorID, ownerID)
+	if err != nil {
+		http.Error(w, "Error scheduling erasure", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error scheduling erasure", "error", err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/owners/%s/erasures/%s", url.PathEscape(ownerID), erasure.Id))
+	writeJSON(w, http.StatusAccepted, erasure)
+}
+
+func (h *OwnerHandler) GetErasure(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, _, ownerID, ok := authorizePathOwner(w, r)
+	if !ok {
+		return
+	}
+
+	erasure, err := h.Service.GetErasure(ctx, ownerID, r.PathValue("erasure_id"))
+	if errors.Is(err, entity.ErrErasureNotFound) {
+		http.Error(w, "Erasure not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving erasure", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error retrieving erasure", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, erasure)
+}
@@ -0,0 +1,101 @@
+package http_handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockOwnerService struct{}
+
+func (m *MockOwnerService) Export(ctx context.Context, actorId string, ownerId string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("zip")), nil
+}
+
+func (m *MockOwnerService) Erase(ctx context.Context, actorId string, ownerId string) (*entity.OwnerErasureResponse, error) {
+	return &entity.OwnerErasureResponse{Id: "erasure1", OwnerId: ownerId, RequestedBy: actorId, Status: entity.ErasurePending}, nil
+}
+
+func (m *MockOwnerService) GetErasure(ctx context.Context, ownerId string, erasureId string) (*entity.OwnerErasureResponse, error) {
+	if erasureId != "erasure1" {
+		return nil, entity.ErrErasureNotFound
+	}
+	return &entity.OwnerErasureResponse{Id: erasureId, OwnerId: ownerId, Status: entity.ErasureCompleted}, nil
+}
+
+func newOwnerMux() *http.ServeMux {
+	handler := &OwnerHandler{Service: &MockOwnerService{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/owners/{id}", handler.Erase)
+	mux.HandleFunc("/owners/{id}/export", handler.Export)
+	mux.HandleFunc("/owners/{id}/erasures/{erasure_id}", handler.GetErasure)
+	return mux
+}
+
+func TestOwnerHandler_OnlyOwnerOrAdmin(t *testing.T) {
+	mux := newOwnerMux()
+
+	cases := []struct {
+		name      string
+		principal *entity.Principal
+		status    int
+	}{
+		{"owner", &entity.Principal{OwnerId: "123"}, http.StatusOK},
+		{"admin", &entity.Principal{OwnerId: "1", Roles: []string{entity.RoleAdmin}}, http.StatusOK},
+		{"other owner", &entity.Principal{OwnerId: "456"}, http.StatusForbidden},
+		{"api key", &entity.Principal{OwnerId: "123", APIKeyId: "key1", Scopes: []string{entity.ScopeRead, entity.ScopeDownload}}, http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/owners/123/export", nil)
+		req = req.WithContext(withPrincipal(req.Context(), c.principal))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, w.Result().StatusCode)
+		}
+	}
+}
+
+func TestOwnerHandler_Export(t *testing.T) {
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/owners/123/export", nil))
+	w := httptest.NewRecorder()
+
+	newOwnerMux().ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Type") != "application/zip" || w.Body.String() != "zip" {
+		t.Errorf("Expected the export archive, got %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestOwnerHandler_EraseAndReceipt(t *testing.T) {
+	mux := newOwnerMux()
+
+	req := authenticated(httptest.NewRequest(http.MethodDelete, "/owners/123", nil))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, w.Result().StatusCode)
+	}
+	location := w.Header().Get("Location")
+	if location != "/owners/123/erasures/erasure1" {
+		t.Fatalf("Expected the erasure's location, got %q", location)
+	}
+
+	for path, status := range map[string]int{location: http.StatusOK, "/owners/123/erasures/unknown": http.StatusNotFound} {
+		req = authenticated(httptest.NewRequest(http.MethodGet, path, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, w.Result().StatusCode)
+		}
+	}
+}
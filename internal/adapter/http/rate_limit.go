@@ -0,0 +1,52 @@
+package http_handler
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+// RateLimit limits each authenticated owner to limit requests to next,
+// with a bucket per name, so it must run after Authenticate. If the limiter
+// itself fails the request is let through rather than turning an outage of
+// the shared state into an outage of the API.
+func RateLimit(limiter port.RateLimiter, name string, limit entity.RateLimit, logger *slog.Logger) func(http.Handler) http.Handler {
+	logger = loggerOrDefault(logger)
+
+	return func(next http.Handler) http.Handler {
+		if !limit.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			principal, ok := principalFrom(ctx)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter, err := limiter.Allow(ctx, name+":"+principal.OwnerId, limit)
+			if err != nil {
+				logger.WarnContext(ctx, "Rate limiter unavailable, allowing request", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !allowed {
+				writeTooManyRequests(w, "Rate limit exceeded", int(math.Ceil(retryAfter.Seconds())))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeTooManyRequests(w http.ResponseWriter, message string, retryAfterSeconds int) {
+	w.Header().Set("Retry-After", strconv.Itoa(max(retryAfterSeconds, 1)))
+	http.Error(w, message, http.StatusTooManyRequests)
+}
@@ -0,0 +1,76 @@
+package http_handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// MockRateLimiter allows limit.Requests requests per key, then asks callers
+// to wait a second and a half.
+type MockRateLimiter struct {
+	taken map[string]int
+	err   error
+}
+
+func (m *MockRateLimiter) Allow(ctx context.Context, key string, limit entity.RateLimit) (bool, time.Duration, error) {
+	if m.err != nil {
+		return false, 0, m.err
+	}
+	if m.taken[key] >= limit.Requests {
+		return false, 1500 * time.Millisecond, nil
+	}
+	m.taken[key]++
+	return true, 0, nil
+}
+
+func TestRateLimit_PerOwner(t *testing.T) {
+	limiter := &MockRateLimiter{taken: make(map[string]int)}
+	limit := entity.RateLimit{Requests: 2, Period: time.Minute}
+	handler := Authenticate(&MockUserPort{}, &MockAPIKeyService{}, &MockAuditRepository{}, nil)(
+		RateLimit(limiter, "/video", limit, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})),
+	)
+
+	send := func(token string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/video", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := send("Bearer user"); resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: expected status %d, got %d", i+1, http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	resp := send("Bearer user")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("Expected 429 with Retry-After 2, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	// Other owners have buckets of their own.
+	if resp := send("Bearer admin"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected another owner to be allowed, got %d", resp.StatusCode)
+	}
+}
+
+func TestRateLimit_FailsOpen(t *testing.T) {
+	limiter := &MockRateLimiter{err: errors.New("database unavailable")}
+	handler := RateLimit(limiter, "/video", entity.RateLimit{Requests: 1, Period: time.Minute}, nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, authenticated(httptest.NewRequest(http.MethodPost, "/video", nil)))
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Errorf("Expected the request to be allowed when the limiter fails, got %d", w.Result().StatusCode)
+	}
+}
@@ -0,0 +1,42 @@
+package http_handler
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID keeps caller supplied ids short and printable so they are
+// safe to echo back and to log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID propagates the caller's X-Request-ID, or assigns a new one, and
+// stores it in the request context so every log line carries it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := logging.With(r.Context(), slog.String(logging.RequestID, requestID))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loggerOrDefault lets handlers built without a logger, as in tests, fall
+// back to the default one.
+func loggerOrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+
+	return logger
+}
@@ -0,0 +1,37 @@
+package http_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+func TestRequestID_PropagatesOrAssigns(t *testing.T) {
+	cases := map[string]bool{
+		"abc-123":           true,
+		"":                  false,
+		"bad id\nInjected:": false,
+	}
+	for header, propagated := range cases {
+		var seen string
+		handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = logging.RequestIDFrom(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/zips", nil)
+		req.Header.Set(requestIDHeader, header)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		returned := w.Result().Header.Get(requestIDHeader)
+		if seen == "" || seen != returned {
+			t.Errorf("header %q: expected context and response ids to match, got %q and %q", header, seen, returned)
+		}
+		if (returned == header) != propagated {
+			t.Errorf("header %q: expected propagated=%t, got %q", header, propagated, returned)
+		}
+	}
+}
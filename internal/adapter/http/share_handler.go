@@ -0,0 +1,243 @@
+package http_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+type ShareHandler struct {
+	Service port.ShareService
+	Logger  *slog.Logger
+}
+
+func (h *ShareHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
+}
+
+type createShareGrantRequest struct {
+	GranteeId  string `json:"grantee_id"`
+	Permission string `json:"permission"`
+}
+
+type createShareLinkRequest struct {
+	Version          int `json:"version"`
+	ExpiresInSeconds int `json:"expires_in_seconds"`
+	MaxDownloads     int `json:"max_downloads"`
+}
+
+func (h *ShareHandler) Grants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.CreateGrant(w, r)
+	case http.MethodGet:
+		h.GetGrants(w, r)
+	default:
+		http.Error(w, "Only GET and POST methods are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ShareHandler) CreateGrant(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	var body createShareGrantRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := h.Service.Grant(ctx, r.PathValue("id"), ownerID, body.GranteeId, body.Permission)
+	if err != nil {
+		h.writeError(ctx, w, "Error sharing video", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, grant)
+}
+
+func (h *ShareHandler) GetGrants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	grants, err := h.Service.GetGrants(ctx, r.PathValue("id"), ownerID)
+	if err != nil {
+		h.writeError(ctx, w, "Error retrieving shares", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, grants)
+}
+
+func (h *ShareHandler) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	err := h.Service.RevokeGrant(ctx, r.PathValue("id"), r.PathValue("share_id"), ownerID)
+	if err != nil {
+		h.writeError(ctx, w, "Error revoking share", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShareHandler) Links(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.CreateLink(w, r)
+	case http.MethodGet:
+		h.GetLinks(w, r)
+	default:
+		http.Error(w, "Only GET and POST methods are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ShareHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	var body createShareLinkRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if body.ExpiresInSeconds < 0 {
+		http.Error(w, "Invalid expires_in_seconds", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.Service.CreateLink(ctx, r.PathValue("id"), ownerID, body.Version, time.Duration(body.ExpiresInSeconds)*time.Second, body.MaxDownloads)
+	if err != nil {
+		h.writeError(ctx, w, "Error creating link", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, link)
+}
+
+func (h *ShareHandler) GetLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	links, err := h.Service.GetLinks(ctx, r.PathValue("id"), ownerID)
+	if err != nil {
+		h.writeError(ctx, w, "Error retrieving links", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, links)
+}
+
+func (h *ShareHandler) RevokeLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	err := h.Service.RevokeLink(ctx, r.PathValue("id"), r.PathValue("link_id"), ownerID)
+	if err != nil {
+		h.writeError(ctx, w, "Error revoking link", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DownloadShared serves a public link. It is mounted without
+// authentication: the signed token is the credential.
+func (h *ShareHandler) DownloadShared(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	file, err := h.Service.DownloadShared(ctx, r.PathValue("token"))
+	if err != nil {
+		h.writeError(ctx, w, "Error downloading video", err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=archive.zip")
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-store")
+
+	_, err = io.Copy(w, file)
+	if err != nil {
+		h.logger().WarnContext(ctx, "Error writing shared file to response", "error", err)
+	}
+}
+
+func (h *ShareHandler) writeError(ctx context.Context, w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, entity.ErrVideoNotFound):
+		http.Error(w, "Video not found", http.StatusNotFound)
+	case errors.Is(err, entity.ErrShareNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entity.ErrShareLinkUnavailable):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, entity.ErrVideoExpired):
+		http.Error(w, "Video archives have expired", http.StatusGone)
+	case errors.Is(err, entity.ErrVideoNotReady):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, entity.ErrShareLinksDisabled):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	case errors.Is(err, entity.ErrInvalidParameters):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, message, "error", err)
+	}
+}
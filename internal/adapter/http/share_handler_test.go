@@ -0,0 +1,90 @@
+package http_handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockShareService struct{}
+
+func (m *MockShareService) Grant(ctx context.Context, videoId string, ownerId string, granteeId string, permission string) (*entity.ShareGrantResponse, error) {
+	if permission != entity.ScopeRead && permission != entity.ScopeDownload {
+		return nil, entity.ErrInvalidParameters
+	}
+	return &entity.ShareGrantResponse{Id: "grant1", VideoId: videoId, GranteeId: granteeId, Permission: permission}, nil
+}
+
+func (m *MockShareService) GetGrants(ctx context.Context, videoId string, ownerId string) ([]entity.ShareGrantResponse, error) {
+	return []entity.ShareGrantResponse{}, nil
+}
+
+func (m *MockShareService) RevokeGrant(ctx context.Context, videoId string, grantId string, ownerId string) error {
+	return entity.ErrShareNotFound
+}
+
+func (m *MockShareService) CreateLink(ctx context.Context, videoId string, ownerId string, version int, ttl time.Duration, maxDownloads int) (*entity.ShareLinkResponse, error) {
+	return &entity.ShareLinkResponse{Id: "link1", VideoId: videoId, Url: "/shared/token"}, nil
+}
+
+func (m *MockShareService) GetLinks(ctx context.Context, videoId string, ownerId string) ([]entity.ShareLinkResponse, error) {
+	return []entity.ShareLinkResponse{}, nil
+}
+
+func (m *MockShareService) RevokeLink(ctx context.Context, videoId string, linkId string, ownerId string) error {
+	return nil
+}
+
+func (m *MockShareService) DownloadShared(ctx context.Context, token string) (io.Reader, error) {
+	switch token {
+	case "valid":
+		return strings.NewReader("zip"), nil
+	case "used":
+		return nil, entity.ErrShareLinkUnavailable
+	}
+	return nil, entity.ErrShareNotFound
+}
+
+func TestShareHandler_DownloadShared(t *testing.T) {
+	handler := &ShareHandler{Service: &MockShareService{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shared/{token}", handler.DownloadShared)
+
+	for token, status := range map[string]int{"valid": http.StatusOK, "used": http.StatusGone, "forged": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, "/shared/"+token, nil)
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", token, status, w.Result().StatusCode)
+		}
+	}
+}
+
+func TestShareHandler_CreateGrant(t *testing.T) {
+	handler := &ShareHandler{Service: &MockShareService{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/videos/{id}/shares", handler.Grants)
+
+	for body, status := range map[string]int{
+		`{"grantee_id":"456","permission":"download"}`: http.StatusCreated,
+		`{"grantee_id":"456","permission":"admin"}`:    http.StatusBadRequest,
+		`not json`: http.StatusBadRequest,
+	} {
+		req := authenticated(httptest.NewRequest(http.MethodPost, "/videos/video1/shares", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		if w.Result().StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", body, status, w.Result().StatusCode)
+		}
+	}
+}
@@ -1,19 +1,34 @@
 package http_handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
 	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
 )
 
-const maxUploadSize = 10 << 20 // 10MB
+const (
+	maxUploadSize = 10 << 20 // 10MB
+	// jobsRetryAfterSeconds is a rough guess at how long a running job
+	// takes to free its slot.
+	jobsRetryAfterSeconds = 30
+)
 
 type VideoHandler struct {
-	Service        port.VideoService
-	UserRepository port.UserPort
+	Service port.VideoService
+	Logger  *slog.Logger
+}
+
+func (h *VideoHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
 }
 
 func (h *VideoHandler) GenerateVideoFrames(w http.ResponseWriter, r *http.Request) {
@@ -22,101 +37,151 @@ func (h *VideoHandler) GenerateVideoFrames(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	ownerID := r.URL.Query().Get("owner_id")
-	if ownerID == "" {
-		http.Error(w, "Missing owner_id query parameter", http.StatusBadRequest)
-		return
-	}
-	valid, err := h.UserRepository.ValidateToken(r.Header.Get("Authorization"), ownerID)
-	if err != nil {
-		http.Error(w, "Error validating token", http.StatusInternalServerError)
-		fmt.Println("Validate token error:", err)
-		return
-	}
-	if !valid {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
 		return
 	}
 
-	err = r.ParseMultipartForm(maxUploadSize)
+	err := r.ParseMultipartForm(maxUploadSize)
 	if err != nil {
 		http.Error(w, "Error parsing form data", http.StatusBadRequest)
-		fmt.Println("Parse error:", err)
+		h.logger().WarnContext(ctx, "Error parsing form data", "error", err)
 		return
 	}
 
 	file, header, err := r.FormFile("video")
 	if err != nil {
 		http.Error(w, "Error retrieving the file", http.StatusBadRequest)
-		fmt.Println("File error:", err)
+		h.logger().WarnContext(ctx, "Error retrieving the file", "error", err)
 		return
 	}
 	defer file.Close()
 
-	h.Service.GenerateFrames(file, header, ownerID)
+	video, err := h.Service.GenerateFrames(ctx, file, header, ownerID)
+	if errors.Is(err, entity.ErrShuttingDown) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "Service is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, entity.ErrTooManyJobs) {
+		writeTooManyRequests(w, entity.ErrTooManyJobs.Error(), jobsRetryAfterSeconds)
+		return
+	}
+	if errors.Is(err, entity.ErrQuotaExceeded) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, entity.ErrErasureInProgress) {
+		http.Error(w, entity.ErrErasureInProgress.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, entity.ErrInvalidVideo) || errors.Is(err, entity.ErrInvalidParameters) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.logger().WarnContext(ctx, "Rejected video upload", "error", err)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error processing video", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error generating frames", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, video)
 }
 
+// GetZips lists a page of the owner's videos. The body stays a plain array;
+// the total and the cursor to the next page are sent as headers.
 func (h *VideoHandler) GetZips(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	ownerID := r.URL.Query().Get("owner_id")
-	if ownerID == "" {
-		http.Error(w, "Missing owner_id query parameter", http.StatusBadRequest)
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
 		return
 	}
 
-	valid, err := h.UserRepository.ValidateToken(r.Header.Get("Authorization"), ownerID)
+	filter, err := videoFilterFrom(r)
 	if err != nil {
-		http.Error(w, "Error validating token", http.StatusInternalServerError)
-		fmt.Println("Validate token error:", err)
-		return
-	}
-	if !valid {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	videos, err := h.Service.GetVideos(ownerID)
-
+	page, err := h.Service.GetVideos(ctx, ownerID, filter)
 	if err != nil {
 		http.Error(w, "Error retrieving videos", http.StatusInternalServerError)
-		fmt.Println("Get videos error:", err)
+		h.logger().ErrorContext(ctx, "Error retrieving videos", "error", err)
 		return
 	}
 
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		next := *r.URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(videos)
+	err = json.NewEncoder(w).Encode(page.Videos)
 	if err != nil {
 		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-		fmt.Println("Error encoding response:", err)
+		h.logger().ErrorContext(ctx, "Error encoding response", "error", err)
 		return
 	}
 }
 
-func (h *VideoHandler) DownloadZip(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
-		return
+// videoFilterFrom reads the listing's query parameters: status,
+// created_after and created_before as RFC 3339 times, sort, limit and
+// cursor.
+func videoFilterFrom(r *http.Request) (entity.VideoFilter, error) {
+	query := r.URL.Query()
+	filter := entity.VideoFilter{Status: query.Get("status")}
+
+	var err error
+	filter.Sort, filter.Descending, err = entity.ParseVideoSort(query.Get("sort"))
+	if err != nil {
+		return filter, fmt.Errorf("Invalid sort query parameter")
 	}
 
-	ownerID := r.URL.Query().Get("owner_id")
-	if ownerID == "" {
-		http.Error(w, "Missing owner_id query parameter", http.StatusBadRequest)
-		return
+	for name, bound := range map[string]*time.Time{"created_after": &filter.CreatedAfter, "created_before": &filter.CreatedBefore} {
+		if value := query.Get(name); value != "" {
+			*bound, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("Invalid %s query parameter", name)
+			}
+		}
 	}
 
-	valid, err := h.UserRepository.ValidateToken(r.Header.Get("Authorization"), ownerID)
-	if err != nil {
-		http.Error(w, "Error validating token", http.StatusInternalServerError)
-		fmt.Println("Validate token error:", err)
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 {
+			return filter, fmt.Errorf("Invalid limit query parameter")
+		}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		filter.After, err = entity.ParseVideoCursor(value, filter)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid cursor query parameter")
+		}
+	}
+
+	return filter, nil
+}
+
+func (h *VideoHandler) DownloadZip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	if !valid {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeDownload)
+	if !ok {
 		return
 	}
 
@@ -126,14 +191,40 @@ func (h *VideoHandler) DownloadZip(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, err := h.Service.DownloadZip(videoID, ownerID)
+	version := 0
+	if rawVersion := r.URL.Query().Get("version"); rawVersion != "" {
+		var err error
+		version, err = strconv.Atoi(rawVersion)
+		if err != nil || version < 1 {
+			http.Error(w, "Invalid version query parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, err := h.Service.DownloadZip(ctx, videoID, ownerID, version)
+	if errors.Is(err, entity.ErrVideoNotFound) {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, entity.ErrVideoExpired) {
+		http.Error(w, "Video archives have expired", http.StatusGone)
+		return
+	}
+	if errors.Is(err, entity.ErrVideoNotReady) {
+		http.Error(w, entity.ErrVideoNotReady.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Error downloading video", http.StatusInternalServerError)
-		fmt.Println("Error downloading video:", err)
+		h.logger().ErrorContext(ctx, "Error downloading video", "error", err)
 		return
 	}
 
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", videoID))
+	filename := fmt.Sprintf("%s.zip", videoID)
+	if version > 1 {
+		filename = entity.GetArchiveKey(videoID, version)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	w.Header().Set("Content-Type", "application/octet-stream")
 
 	_, err = io.Copy(w, file)
@@ -142,3 +233,158 @@ func (h *VideoHandler) DownloadZip(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *VideoHandler) CancelVideo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	video, err := h.Service.Cancel(ctx, r.PathValue("id"), ownerID)
+	if errors.Is(err, entity.ErrVideoNotFound) {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, entity.ErrStatusConflict) {
+		http.Error(w, "Video is not processing", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error cancelling video", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error cancelling video", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, video)
+}
+
+func (h *VideoHandler) DeleteVideo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	err := h.Service.Delete(ctx, r.PathValue("id"), ownerID)
+	if errors.Is(err, entity.ErrVideoNotFound) {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting video", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error deleting video", "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type reprocessRequest struct {
+	IntervalSeconds int `json:"interval_seconds"`
+}
+
+func (h *VideoHandler) ReprocessVideo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	var body reprocessRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	video, err := h.Service.Reprocess(ctx, r.PathValue("id"), ownerID, body.IntervalSeconds)
+	writeReprocessResult(ctx, w, h.logger(), video, err)
+}
+
+func writeReprocessResult(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, video *entity.VideoFileResponse, err error) {
+	switch {
+	case errors.Is(err, entity.ErrVideoNotFound):
+		http.Error(w, "Video not found", http.StatusNotFound)
+	case errors.Is(err, entity.ErrInvalidParameters):
+		http.Error(w, "Invalid processing parameters", http.StatusBadRequest)
+	case errors.Is(err, entity.ErrStatusConflict):
+		http.Error(w, "Video is already processing", http.StatusConflict)
+	case errors.Is(err, entity.ErrSourceUnavailable), errors.Is(err, entity.ErrVideoExpired):
+		http.Error(w, "Original video is no longer available", http.StatusGone)
+	case errors.Is(err, entity.ErrShuttingDown):
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "Service is shutting down", http.StatusServiceUnavailable)
+	case errors.Is(err, entity.ErrTooManyJobs):
+		writeTooManyRequests(w, entity.ErrTooManyJobs.Error(), jobsRetryAfterSeconds)
+	case errors.Is(err, entity.ErrQuotaExceeded):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, entity.ErrErasureInProgress):
+		http.Error(w, entity.ErrErasureInProgress.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, "Error reprocessing video", http.StatusInternalServerError)
+		logger.ErrorContext(ctx, "Error reprocessing video", "error", err)
+	default:
+		writeJSON(w, http.StatusAccepted, video)
+	}
+}
+
+func (h *VideoHandler) GetArchives(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	archives, err := h.Service.GetArchives(ctx, r.PathValue("id"), ownerID)
+	if errors.Is(err, entity.ErrVideoNotFound) {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving archives", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error retrieving archives", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, archives)
+}
+
+func (h *VideoHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	usage, err := h.Service.GetUsage(ctx, ownerID)
+	if err != nil {
+		http.Error(w, "Error retrieving usage", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error retrieving usage", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, usage)
+}
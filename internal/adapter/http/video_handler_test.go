@@ -2,6 +2,10 @@ package http_handler
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
@@ -14,30 +18,112 @@ import (
 
 type MockVideoService struct{}
 
-func (m *MockVideoService) GenerateFrames(file multipart.File, header *multipart.FileHeader, ownerID string) error {
+func (m *MockVideoService) GenerateFrames(ctx context.Context, file multipart.File, header *multipart.FileHeader, ownerID string) (*entity.VideoFileResponse, error) {
 	// Mock the GenerateFrames method
-	return nil
+	switch header.Filename {
+	case "dummy.avi":
+		return nil, fmt.Errorf("%w: unsupported file format: %s", entity.ErrInvalidVideo, header.Filename)
+	case "unstored.mp4":
+		return nil, errors.New("s3: connection reset")
+	}
+	return &entity.VideoFileResponse{Id: "908ba06a-a155-46da-96bd-a9db58cbc56b", OwnerId: ownerID, Status: "processing"}, nil
 }
 
-func (m *MockVideoService) GetVideos(ownerID string) ([]entity.VideoFileResponse, error) {
+func (m *MockVideoService) GetVideos(ctx context.Context, ownerID string, filter entity.VideoFilter) (*entity.VideoPage, error) {
 	// Return a mock video list
-	return []entity.VideoFileResponse{
-		{Id: "908ba06a-a155-46da-96bd-a9db58cbc56b", OwnerId: "123"},
-		{Id: "0d90a1d2-031e-4912-81b5-165fbc8b3a73", OwnerId: "123"},
-	}, nil
+	page := &entity.VideoPage{
+		Videos: []entity.VideoFileResponse{
+			{Id: "908ba06a-a155-46da-96bd-a9db58cbc56b", OwnerId: "123"},
+			{Id: "0d90a1d2-031e-4912-81b5-165fbc8b3a73", OwnerId: "123"},
+		},
+		Total: 3,
+	}
+	if filter.After == nil {
+		page.NextCursor = "next"
+	}
+	return page, nil
+}
+
+func (m *MockVideoService) Reprocess(ctx context.Context, videoID, ownerID string, intervalSeconds int) (*entity.VideoFileResponse, error) {
+	if intervalSeconds < 0 {
+		return nil, entity.ErrInvalidParameters
+	}
+	if videoID == "draining" {
+		return nil, entity.ErrShuttingDown
+	}
+	return &entity.VideoFileResponse{Id: videoID, OwnerId: ownerID, Status: "processing"}, nil
+}
+
+func (m *MockVideoService) GetArchives(ctx context.Context, videoID, ownerID string) ([]entity.VideoArchiveResponse, error) {
+	return []entity.VideoArchiveResponse{{Version: 1}}, nil
+}
+
+func (m *MockVideoService) GetUsage(ctx context.Context, ownerID string) (*entity.UsageResponse, error) {
+	return &entity.UsageResponse{Videos: 2, StorageBytes: 1024, Limits: entity.QuotaResponse{MaxVideos: 10}}, nil
 }
 
-func (m *MockVideoService) DownloadZip(videoID, ownerID string) (io.Reader, error) {
+func (m *MockVideoService) DownloadZip(ctx context.Context, videoID, ownerID string, version int) (io.Reader, error) {
+	if videoID == "expired" {
+		return nil, entity.ErrVideoExpired
+	}
+	if videoID == "processing" {
+		return nil, entity.ErrVideoNotReady
+	}
 	// Return a mock file content
 	return ioutil.NopCloser(bytes.NewReader([]byte("mock video content"))), nil
 }
 
+func (m *MockVideoService) Cancel(ctx context.Context, videoID, ownerID string) (*entity.VideoFileResponse, error) {
+	switch videoID {
+	case "missing":
+		return nil, entity.ErrVideoNotFound
+	case "finished":
+		return nil, entity.ErrStatusConflict
+	}
+	return &entity.VideoFileResponse{Id: videoID, OwnerId: ownerID, Status: "cancelled"}, nil
+}
+
+func (m *MockVideoService) Delete(ctx context.Context, videoID, ownerID string) error {
+	switch videoID {
+	case "foreign":
+		return entity.ErrVideoNotFound
+	case "broken":
+		return fmt.Errorf("storage unavailable")
+	}
+	return nil
+}
+
+type MockUserPort struct{}
+
+func (m *MockUserPort) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	switch token {
+	case "Bearer user":
+		return &entity.Principal{OwnerId: "123"}, nil
+	case "Bearer admin":
+		return &entity.Principal{OwnerId: "1", Roles: []string{entity.RoleAdmin}}, nil
+	case "Bearer broken":
+		return nil, fmt.Errorf("unexpected response")
+	case "Bearer down":
+		return nil, fmt.Errorf("%w: circuit open", entity.ErrAuthUnavailable)
+	}
+	return nil, entity.ErrUnauthorized
+}
+
+func (m *MockUserPort) GetEmail(ctx context.Context, ownerID string) (string, error) {
+	return "user@example.com", nil
+}
+
+// authenticated marks req as coming from owner 123, as Authenticate would.
+func authenticated(req *http.Request) *http.Request {
+	return req.WithContext(withPrincipal(req.Context(), &entity.Principal{OwnerId: "123"}))
+}
+
 func TestGenerateVideoFrames_MethodNotPost(t *testing.T) {
 	handler := &VideoHandler{
 		Service: &MockVideoService{},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/generate-video-frames?owner_id=123", nil)
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/generate-video-frames", nil))
 	w := httptest.NewRecorder()
 
 	handler.GenerateVideoFrames(w, req)
@@ -48,7 +134,7 @@ func TestGenerateVideoFrames_MethodNotPost(t *testing.T) {
 	}
 }
 
-func TestGenerateVideoFrames_MissingOwnerID(t *testing.T) {
+func TestGenerateVideoFrames_Unauthenticated(t *testing.T) {
 	handler := &VideoHandler{
 		Service: &MockVideoService{},
 	}
@@ -59,8 +145,8 @@ func TestGenerateVideoFrames_MissingOwnerID(t *testing.T) {
 	handler.GenerateVideoFrames(w, req)
 
 	resp := w.Result()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 }
 
@@ -81,7 +167,7 @@ func TestGenerateVideoFrames_Success(t *testing.T) {
 	writer.WriteField("owner_id", "123")
 	writer.Close()
 
-	req := httptest.NewRequest(http.MethodPost, "/generate-video-frames?owner_id=123", body)
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/generate-video-frames", body))
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	w := httptest.NewRecorder()
 
@@ -93,12 +179,39 @@ func TestGenerateVideoFrames_Success(t *testing.T) {
 	}
 }
 
+func TestGenerateVideoFrames_ErrorStatus(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	for filename, status := range map[string]int{"dummy.avi": http.StatusBadRequest, "unstored.mp4": http.StatusInternalServerError} {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("video", filename)
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write([]byte("dummy video file content"))
+		writer.Close()
+
+		req := authenticated(httptest.NewRequest(http.MethodPost, "/generate-video-frames", body))
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+
+		handler.GenerateVideoFrames(w, req)
+
+		if w.Result().StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", filename, status, w.Result().StatusCode)
+		}
+	}
+}
+
 func TestGetZips_MethodNotGet(t *testing.T) {
 	handler := &VideoHandler{
 		Service: &MockVideoService{},
 	}
 
-	req := httptest.NewRequest(http.MethodPost, "/get-zips?owner_id=123", nil)
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/get-zips", nil))
 	w := httptest.NewRecorder()
 
 	handler.GetZips(w, req)
@@ -109,7 +222,7 @@ func TestGetZips_MethodNotGet(t *testing.T) {
 	}
 }
 
-func TestGetZips_MissingOwnerID(t *testing.T) {
+func TestGetZips_Unauthenticated(t *testing.T) {
 	handler := &VideoHandler{
 		Service: &MockVideoService{},
 	}
@@ -120,8 +233,8 @@ func TestGetZips_MissingOwnerID(t *testing.T) {
 	handler.GetZips(w, req)
 
 	resp := w.Result()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 }
 
@@ -130,7 +243,7 @@ func TestGetZips_Success(t *testing.T) {
 		Service: &MockVideoService{},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/get-zips?owner_id=123", nil)
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/get-zips", nil))
 	w := httptest.NewRecorder()
 
 	handler.GetZips(w, req)
@@ -152,12 +265,47 @@ func TestGetZips_Success(t *testing.T) {
 	}
 }
 
+func TestGetZips_Pagination(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/zips?status=ready_to_download&limit=2", nil))
+	w := httptest.NewRecorder()
+
+	handler.GetZips(w, req)
+
+	if w.Header().Get("X-Total-Count") != "3" || w.Header().Get("X-Next-Cursor") != "next" {
+		t.Errorf("expected the total and next cursor headers, got %v", w.Header())
+	}
+	if link := w.Header().Get("Link"); link != `</zips?cursor=next&limit=2&status=ready_to_download>; rel="next"` {
+		t.Errorf("expected a link to the next page, got %q", link)
+	}
+}
+
+func TestGetZips_InvalidQuery(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	for _, query := range []string{"sort=size", "created_after=yesterday", "limit=0", "cursor=forged"} {
+		req := authenticated(httptest.NewRequest(http.MethodGet, "/zips?"+query, nil))
+		w := httptest.NewRecorder()
+
+		handler.GetZips(w, req)
+
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, w.Result().StatusCode)
+		}
+	}
+}
+
 func TestDownloadZip_MethodNotGet(t *testing.T) {
 	handler := &VideoHandler{
 		Service: &MockVideoService{},
 	}
 
-	req := httptest.NewRequest(http.MethodPost, "/download-zip?owner_id=123&video_id=1", nil)
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/download-zip?video_id=1", nil))
 	w := httptest.NewRecorder()
 
 	handler.DownloadZip(w, req)
@@ -168,7 +316,7 @@ func TestDownloadZip_MethodNotGet(t *testing.T) {
 	}
 }
 
-func TestDownloadZip_MissingOwnerID(t *testing.T) {
+func TestDownloadZip_Unauthenticated(t *testing.T) {
 	handler := &VideoHandler{
 		Service: &MockVideoService{},
 	}
@@ -179,8 +327,8 @@ func TestDownloadZip_MissingOwnerID(t *testing.T) {
 	handler.DownloadZip(w, req)
 
 	resp := w.Result()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 }
 
@@ -189,7 +337,7 @@ func TestDownloadZip_MissingVideoID(t *testing.T) {
 		Service: &MockVideoService{},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/download-zip?owner_id=123", nil)
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/download-zip", nil))
 	w := httptest.NewRecorder()
 
 	handler.DownloadZip(w, req)
@@ -205,7 +353,7 @@ func TestDownloadZip_Success(t *testing.T) {
 		Service: &MockVideoService{},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/download-zip?owner_id=123&video_id=1", nil)
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/download-zip?video_id=1", nil))
 	w := httptest.NewRecorder()
 
 	handler.DownloadZip(w, req)
@@ -231,3 +379,193 @@ func TestDownloadZip_Success(t *testing.T) {
 		t.Errorf("expected response body %s, got %s", expectedBody, body)
 	}
 }
+
+func TestCancelVideo_MethodNotPost(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/videos/1/cancel", nil))
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.CancelVideo(w, req)
+
+	if w.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Result().StatusCode)
+	}
+}
+
+func TestCancelVideo_StatusCodes(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	cases := map[string]int{
+		"1":        http.StatusOK,
+		"missing":  http.StatusNotFound,
+		"finished": http.StatusConflict,
+	}
+	for videoID, expected := range cases {
+		req := authenticated(httptest.NewRequest(http.MethodPost, "/videos/"+videoID+"/cancel", nil))
+		req.SetPathValue("id", videoID)
+		w := httptest.NewRecorder()
+
+		handler.CancelVideo(w, req)
+
+		if w.Result().StatusCode != expected {
+			t.Errorf("video %s: expected status %d, got %d", videoID, expected, w.Result().StatusCode)
+		}
+	}
+}
+
+func TestDeleteVideo_MethodNotDelete(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/videos/1", nil))
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.DeleteVideo(w, req)
+
+	if w.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Result().StatusCode)
+	}
+}
+
+func TestDeleteVideo_StatusCodes(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	cases := map[string]int{
+		"1":       http.StatusNoContent,
+		"foreign": http.StatusNotFound,
+		"broken":  http.StatusInternalServerError,
+	}
+	for videoID, expected := range cases {
+		req := authenticated(httptest.NewRequest(http.MethodDelete, "/videos/"+videoID, nil))
+		req.SetPathValue("id", videoID)
+		w := httptest.NewRecorder()
+
+		handler.DeleteVideo(w, req)
+
+		if w.Result().StatusCode != expected {
+			t.Errorf("video %s: expected status %d, got %d", videoID, expected, w.Result().StatusCode)
+		}
+	}
+}
+
+func TestReprocessVideo_Accepted(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/videos/1/reprocess", bytes.NewBufferString(`{"interval_seconds": 2}`)))
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.ReprocessVideo(w, req)
+
+	if w.Result().StatusCode != http.StatusAccepted {
+		t.Errorf("expected status %d, got %d", http.StatusAccepted, w.Result().StatusCode)
+	}
+}
+
+func TestReprocessVideo_InvalidParameters(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/videos/1/reprocess", bytes.NewBufferString(`{"interval_seconds": -5}`)))
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.ReprocessVideo(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+	}
+}
+
+func TestDownloadZip_InvalidVersion(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/download-zip?video_id=1&version=abc", nil))
+	w := httptest.NewRecorder()
+
+	handler.DownloadZip(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+	}
+}
+
+func TestReprocessVideo_ShuttingDown(t *testing.T) {
+	handler := &VideoHandler{
+		Service: &MockVideoService{},
+	}
+
+	req := authenticated(httptest.NewRequest(http.MethodPost, "/videos/draining/reprocess", nil))
+	req.SetPathValue("id", "draining")
+	w := httptest.NewRecorder()
+
+	handler.ReprocessVideo(w, req)
+
+	if w.Result().StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Result().StatusCode)
+	}
+	if w.Result().Header.Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
+
+func TestGetUsage_Success(t *testing.T) {
+	handler := &VideoHandler{Service: &MockVideoService{}}
+
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/usage", nil))
+	w := httptest.NewRecorder()
+
+	handler.GetUsage(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var usage entity.UsageResponse
+	err := json.NewDecoder(resp.Body).Decode(&usage)
+	if err != nil || usage.Videos != 2 || usage.Limits.MaxVideos != 10 {
+		t.Errorf("expected usage against limits, got %+v %v", usage, err)
+	}
+}
+
+func TestDownloadZip_Expired(t *testing.T) {
+	handler := &VideoHandler{Service: &MockVideoService{}}
+
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/zip/download?video_id=expired", nil))
+	w := httptest.NewRecorder()
+
+	handler.DownloadZip(w, req)
+
+	if w.Result().StatusCode != http.StatusGone {
+		t.Errorf("expected status %d, got %d", http.StatusGone, w.Result().StatusCode)
+	}
+}
+
+func TestDownloadZip_NotReady(t *testing.T) {
+	handler := &VideoHandler{Service: &MockVideoService{}}
+
+	req := authenticated(httptest.NewRequest(http.MethodGet, "/zip/download?video_id=processing", nil))
+	w := httptest.NewRecorder()
+
+	handler.DownloadZip(w, req)
+
+	if w.Result().StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, w.Result().StatusCode)
+	}
+}
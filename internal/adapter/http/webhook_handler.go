@@ -0,0 +1,150 @@
+package http_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+type WebhookHandler struct {
+	Service port.WebhookService
+	Logger  *slog.Logger
+}
+
+func (h *WebhookHandler) logger() *slog.Logger {
+	return loggerOrDefault(h.Logger)
+}
+
+type registerWebhookRequest struct {
+	Url string `json:"url"`
+}
+
+func (h *WebhookHandler) Webhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.RegisterWebhook(w, r)
+	case http.MethodGet:
+		h.GetWebhooks(w, r)
+	default:
+		http.Error(w, "Only GET and POST methods are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *WebhookHandler) RegisterWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	var body registerWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.Url == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.Service.Register(ownerID, body.Url)
+	if errors.Is(err, entity.ErrInvalidWebhookURL) {
+		http.Error(w, entity.ErrInvalidWebhookURL.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error registering webhook", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error registering webhook", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.Service.GetWebhooks(ownerID)
+	if err != nil {
+		http.Error(w, "Error retrieving webhooks", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error retrieving webhooks", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeRead)
+	if !ok {
+		return
+	}
+
+	webhookID := r.URL.Query().Get("webhook_id")
+	if webhookID == "" {
+		http.Error(w, "Missing webhook_id query parameter", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.Service.GetDeliveries(webhookID, ownerID)
+	if errors.Is(err, entity.ErrWebhookNotFound) {
+		http.Error(w, entity.ErrWebhookNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving deliveries", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error retrieving deliveries", "error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, ownerID, ok := authorizeOwner(w, r, entity.ScopeUpload)
+	if !ok {
+		return
+	}
+
+	deliveryID := r.URL.Query().Get("delivery_id")
+	if deliveryID == "" {
+		http.Error(w, "Missing delivery_id query parameter", http.StatusBadRequest)
+		return
+	}
+
+	err := h.Service.Replay(deliveryID, ownerID)
+	if errors.Is(err, entity.ErrDeliveryNotFound) || errors.Is(err, entity.ErrWebhookNotFound) {
+		http.Error(w, entity.ErrDeliveryNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error replaying delivery", http.StatusInternalServerError)
+		h.logger().ErrorContext(ctx, "Error replaying delivery", "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "video"
+
+var (
+	durationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+	sizeBuckets     = prometheus.ExponentialBuckets(1<<20, 4, 8) // 1MiB to 16GiB
+	frameBuckets    = prometheus.ExponentialBuckets(1, 2, 14)
+)
+
+// PrometheusMetrics implements port.VideoMetrics and instruments the HTTP
+// routes, registering everything on its own registry.
+type PrometheusMetrics struct {
+	Registry *prometheus.Registry
+
+	uploads          prometheus.Counter
+	successes        prometheus.Counter
+	failures         *prometheus.CounterVec
+	uploadSize       prometheus.Histogram
+	ffmpegDuration   prometheus.Histogram
+	zipDuration      prometheus.Histogram
+	storageDuration  *prometheus.HistogramVec
+	framesPerVideo   prometheus.Histogram
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight prometheus.Gauge
+}
+
+func NewPrometheusMetrics() *PrometheusMetrics {
+	registry := prometheus.NewRegistry()
+	m := &PrometheusMetrics{
+		Registry: registry,
+		uploads: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "uploads_total",
+			Help:      "Videos accepted for processing.",
+		}),
+		successes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_succeeded_total",
+			Help:      "Processing jobs that produced an archive.",
+		}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "job_failures_total",
+			Help:      "Failed processing attempts by error class (transient, permanent, exhausted, cancelled).",
+		}, []string{"class"}),
+		uploadSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_size_bytes",
+			Help:      "Size of uploaded videos.",
+			Buckets:   sizeBuckets,
+		}),
+		ffmpegDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "ffmpeg_duration_seconds",
+			Help:      "Time spent extracting frames with ffmpeg.",
+			Buckets:   durationBuckets,
+		}),
+		zipDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "zip_duration_seconds",
+			Help:      "Time spent packing frames into an archive.",
+			Buckets:   durationBuckets,
+		}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_duration_seconds",
+			Help:      "Time spent transferring files to and from object storage.",
+			Buckets:   durationBuckets,
+		}, []string{"operation"}),
+		framesPerVideo: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "frames_per_video",
+			Help:      "Frames extracted from each successfully processed video.",
+			Buckets:   frameBuckets,
+		}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "code"}),
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests currently being served.",
+		}),
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.uploads,
+		m.successes,
+		m.failures,
+		m.uploadSize,
+		m.ffmpegDuration,
+		m.zipDuration,
+		m.storageDuration,
+		m.framesPerVideo,
+		m.requests,
+		m.requestDuration,
+		m.requestsInFlight,
+	)
+
+	return m
+}
+
+// RegisterJobGauges exposes the in-flight jobs of this worker and the
+// processing queue depth, both read at scrape time.
+func (m *PrometheusMetrics) RegisterJobGauges(inFlight func() int, queueDepth func() (int, error)) {
+	m.Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jobs_in_flight",
+			Help:      "Processing jobs running on this worker.",
+		}, func() float64 {
+			return float64(inFlight())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Videos waiting for or undergoing processing across all workers.",
+		}, func() float64 {
+			depth, err := queueDepth()
+			if err != nil {
+				slog.Error("Error reading queue depth", "error", err)
+			}
+			return float64(depth)
+		}),
+	)
+}
+
+func (m *PrometheusMetrics) UploadReceived(sizeBytes int64) {
+	m.uploads.Inc()
+	m.uploadSize.Observe(float64(sizeBytes))
+}
+
+func (m *PrometheusMetrics) JobSucceeded(frameCount int) {
+	m.successes.Inc()
+	m.framesPerVideo.Observe(float64(frameCount))
+}
+
+func (m *PrometheusMetrics) JobFailed(errorClass string) {
+	m.failures.WithLabelValues(errorClass).Inc()
+}
+
+func (m *PrometheusMetrics) ObserveFFmpeg(duration time.Duration) {
+	m.ffmpegDuration.Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) ObserveZip(duration time.Duration) {
+	m.zipDuration.Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) ObserveStorage(operation string, duration time.Duration) {
+	m.storageDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// InstrumentHandler records request count, latency and in-flight requests
+// for next under the given route name.
+func (m *PrometheusMetrics) InstrumentHandler(route string, next http.HandlerFunc) http.HandlerFunc {
+	labels := prometheus.Labels{"route": route}
+	handler := promhttp.InstrumentHandlerInFlight(m.requestsInFlight,
+		promhttp.InstrumentHandlerDuration(m.requestDuration.MustCurryWith(labels),
+			promhttp.InstrumentHandlerCounter(m.requests.MustCurryWith(labels), next),
+		),
+	)
+
+	return handler.ServeHTTP
+}
+
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics_ExposesPipelineAndHTTPMetrics(t *testing.T) {
+	m := NewPrometheusMetrics()
+	m.RegisterJobGauges(func() int { return 2 }, func() (int, error) { return 5, nil })
+	m.UploadReceived(3 << 20)
+	m.JobSucceeded(12)
+	m.JobFailed("transient")
+	m.ObserveStorage("upload", 200*time.Millisecond)
+
+	handler := m.InstrumentHandler("/zips", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/zips?owner_id=1", nil))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	expected := []string{
+		"video_uploads_total 1",
+		"video_jobs_succeeded_total 1",
+		`video_job_failures_total{class="transient"} 1`,
+		`video_storage_duration_seconds_count{operation="upload"} 1`,
+		"video_jobs_in_flight 2",
+		"video_queue_depth 5",
+		`http_requests_total{code="418",method="get",route="/zips"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected metrics to contain %q", line)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const apiKeyColumns = "id, owner_id, name, hash, scopes, expires_at, revoked_at, created_at"
+
+type APIKeyRepository struct {
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) port.APIKeyRepository {
+	return &APIKeyRepository{db: db, logger: logger, timeout: timeout}
+}
+
+func (r *APIKeyRepository) Save(ctx context.Context, key entity.APIKey) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "INSERT INTO api_keys ("+apiKeyColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		key.Id, key.OwnerId, key.Name, key.Hash, key.Scopes, key.ExpiresAt, key.RevokedAt, key.CreatedAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving API key", "error", err)
+	}
+
+	return err
+}
+
+func (r *APIKeyRepository) FindByOwnerId(ctx context.Context, ownerId string) ([]entity.APIKey, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	keys := []entity.APIKey{}
+	rows, err := r.db.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE owner_id = $1 ORDER BY created_at", ownerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying API keys", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning API key", "error", err)
+			return nil, err
+		}
+
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE hash = $1", hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error scanning API key", "error", err)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, ownerId string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, "UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND owner_id = $3 AND revoked_at IS NULL", at, id, ownerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error revoking API key", "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*entity.APIKey, error) {
+	key := entity.APIKey{}
+	err := row.Scan(&key.Id, &key.OwnerId, &key.Name, &key.Hash, &key.Scopes, &key.ExpiresAt, &key.RevokedAt, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewAuditRepository(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) port.AuditRepository {
+	return &AuditRepository{db: db, logger: logger, timeout: timeout}
+}
+
+func (r *AuditRepository) Record(ctx context.Context, entry entity.AuditEntry) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	details := entry.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	_, err := r.db.Exec(ctx, "INSERT INTO audit_log (id, actor_id, action, target_id, details, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		entry.Id, entry.ActorId, entry.Action, entry.TargetId, details, entry.CreatedAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving audit entry", "error", err)
+	}
+
+	return err
+}
+
+func (r *AuditRepository) FindRecent(ctx context.Context, limit int) ([]entity.AuditEntry, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	entries := []entity.AuditEntry{}
+	rows, err := r.db.Query(ctx, "SELECT id, actor_id, action, target_id, details, created_at FROM audit_log ORDER BY created_at DESC LIMIT $1", limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying audit log", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := entity.AuditEntry{}
+		err = rows.Scan(&entry.Id, &entry.ActorId, &entry.Action, &entry.TargetId, &entry.Details, &entry.CreatedAt)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning audit entry", "error", err)
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
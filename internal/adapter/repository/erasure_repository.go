@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	erasureColumns = "id, owner_id, requested_by, status, videos_erased, records_erased, attempts, last_error, requested_at, completed_at"
+
+	claimErasures = `
+	UPDATE owner_erasures SET status = 'running', attempts = attempts + 1, locked_until = NOW() + $2 * INTERVAL '1 millisecond'
+	WHERE id IN (
+		SELECT id FROM owner_erasures
+		WHERE status <> 'completed' AND (locked_until IS NULL OR locked_until < NOW())
+		ORDER BY requested_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + erasureColumns
+)
+
+// ownerRecords deletes the owner's rows outside the videos table. Grants
+// other owners gave them go too, as they name the owner.
+var ownerRecords = []string{
+	"DELETE FROM api_keys WHERE owner_id = $1",
+	"DELETE FROM webhooks WHERE owner_id = $1",
+	"DELETE FROM share_grants WHERE owner_id = $1 OR grantee_id = $1",
+	"DELETE FROM share_links WHERE owner_id = $1",
+	"DELETE FROM owner_usage WHERE owner_id = $1",
+	"DELETE FROM outbox WHERE payload->>'owner_id' = $1",
+	"DELETE FROM video_status_history WHERE owner_id = $1",
+	"DELETE FROM rate_limits WHERE right(key, length($1) + 1) = ':' || $1",
+}
+
+type ErasureRepository struct {
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewErasureRepository(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) port.ErasureRepository {
+	return &ErasureRepository{db: db, logger: logger, timeout: timeout}
+}
+
+func (r *ErasureRepository) Request(ctx context.Context, erasure entity.OwnerErasure) (*entity.OwnerErasure, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	saved, err := scanErasure(r.db.QueryRow(ctx, `
+		INSERT INTO owner_erasures (id, owner_id, requested_by, status, requested_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (owner_id) WHERE status <> 'completed' DO NOTHING
+		RETURNING `+erasureColumns,
+		erasure.Id, erasure.OwnerId, erasure.RequestedBy, erasure.Status, erasure.RequestedAt))
+	if errors.Is(err, pgx.ErrNoRows) {
+		saved, err = scanErasure(r.db.QueryRow(ctx, "SELECT "+erasureColumns+" FROM owner_erasures WHERE owner_id = $1 AND status <> 'completed'", erasure.OwnerId))
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving erasure", logging.OwnerID, erasure.OwnerId, "error", err)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (r *ErasureRepository) FindActive(ctx context.Context, ownerId string) (*entity.OwnerErasure, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	erasure, err := scanErasure(r.db.QueryRow(ctx, "SELECT "+erasureColumns+" FROM owner_erasures WHERE owner_id = $1 AND status <> 'completed'", ownerId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrErasureNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error scanning erasure", logging.OwnerID, ownerId, "error", err)
+		return nil, err
+	}
+
+	return erasure, nil
+}
+
+func (r *ErasureRepository) FindById(ctx context.Context, id string) (*entity.OwnerErasure, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	erasure, err := scanErasure(r.db.QueryRow(ctx, "SELECT "+erasureColumns+" FROM owner_erasures WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrErasureNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error scanning erasure", "error", err)
+		return nil, err
+	}
+
+	return erasure, nil
+}
+
+func (r *ErasureRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]entity.OwnerErasure, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	erasures := []entity.OwnerErasure{}
+	rows, err := r.db.Query(ctx, claimErasures, limit, lease.Milliseconds())
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error claiming erasures", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		erasure, err := scanErasure(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning erasure", "error", err)
+			return nil, err
+		}
+
+		erasures = append(erasures, *erasure)
+	}
+
+	return erasures, rows.Err()
+}
+
+func (r *ErasureRepository) EraseRecords(ctx context.Context, ownerId string) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error starting transaction", logging.OwnerID, ownerId, "error", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var erased int64
+	for _, query := range ownerRecords {
+		tag, err := tx.Exec(ctx, query, ownerId)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error erasing owner records", logging.OwnerID, ownerId, "query", query, "error", err)
+			return 0, err
+		}
+		erased += tag.RowsAffected()
+	}
+
+	return erased, tx.Commit(ctx)
+}
+
+func (r *ErasureRepository) Complete(ctx context.Context, erasure entity.OwnerErasure) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE owner_erasures SET status = $1, videos_erased = $2, records_erased = $3, last_error = '', completed_at = $4, locked_until = NULL WHERE id = $5",
+		entity.ErasureCompleted, erasure.VideosErased, erasure.RecordsErased, erasure.CompletedAt, erasure.Id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error completing erasure", logging.OwnerID, erasure.OwnerId, "error", err)
+	}
+
+	return err
+}
+
+func (r *ErasureRepository) Release(ctx context.Context, erasure entity.OwnerErasure, retryIn time.Duration) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE owner_erasures SET status = $1, videos_erased = $2, records_erased = $3, last_error = $4, locked_until = NOW() + $5 * INTERVAL '1 millisecond' WHERE id = $6",
+		entity.ErasurePending, erasure.VideosErased, erasure.RecordsErased, erasure.LastError, retryIn.Milliseconds(), erasure.Id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error releasing erasure", logging.OwnerID, erasure.OwnerId, "error", err)
+	}
+
+	return err
+}
+
+func scanErasure(row pgx.Row) (*entity.OwnerErasure, error) {
+	erasure := entity.OwnerErasure{}
+	err := row.Scan(&erasure.Id, &erasure.OwnerId, &erasure.RequestedBy, &erasure.Status, &erasure.VideosErased, &erasure.RecordsErased,
+		&erasure.Attempts, &erasure.LastError, &erasure.RequestedAt, &erasure.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &erasure, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"syscall"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresHealthCheck struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresHealthCheck(db *pgxpool.Pool) *PostgresHealthCheck {
+	return &PostgresHealthCheck{db: db}
+}
+
+func (c *PostgresHealthCheck) Name() string {
+	return "postgres"
+}
+
+func (c *PostgresHealthCheck) Check(ctx context.Context) error {
+	return c.db.Ping(ctx)
+}
+
+func (r *S3Repository) Name() string {
+	return "object_storage"
+}
+
+func (r *S3Repository) Check(ctx context.Context) error {
+	_, err := r.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(r.bucketName),
+	})
+	return err
+}
+
+func (r *UserRepository) Name() string {
+	return "user_service"
+}
+
+// Check only verifies the user service answers; any response below 500 means
+// it is up even if the path itself is not routed.
+func (r *UserRepository) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://svc-user-app/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("user service returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+type FFmpegHealthCheck struct {
+	Binary string
+}
+
+func NewFFmpegHealthCheck() *FFmpegHealthCheck {
+	return &FFmpegHealthCheck{Binary: "ffmpeg"}
+}
+
+func (c *FFmpegHealthCheck) Name() string {
+	return "ffmpeg"
+}
+
+func (c *FFmpegHealthCheck) Check(ctx context.Context) error {
+	_, err := exec.LookPath(c.Binary)
+	return err
+}
+
+// DiskHealthCheck fails when the scratch directory used for sources, frames
+// and archives has less than MinFreeBytes available.
+type DiskHealthCheck struct {
+	Dir          string
+	MinFreeBytes uint64
+}
+
+func NewDiskHealthCheck(dir string, minFreeBytes uint64) *DiskHealthCheck {
+	return &DiskHealthCheck{Dir: dir, MinFreeBytes: minFreeBytes}
+}
+
+func (c *DiskHealthCheck) Name() string {
+	return "scratch_disk"
+}
+
+func (c *DiskHealthCheck) Check(ctx context.Context) error {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(c.Dir, &stat)
+	if err != nil {
+		return err
+	}
+
+	free := stat.Bavail * uint64(stat.Bsize)
+	if free < c.MinFreeBytes {
+		return fmt.Errorf("%d bytes free in %s, need at least %d", free, c.Dir, c.MinFreeBytes)
+	}
+
+	return nil
+}
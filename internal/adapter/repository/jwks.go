@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrKeySetUnavailable means the signing keys could not be loaded, as
+// opposed to the token being invalid.
+var ErrKeySetUnavailable = errors.New("signing keys unavailable")
+
+// staticKeyID is the kid a key set with a single shared secret answers to,
+// whatever kid the token carries.
+const staticKeyID = ""
+
+// KeySet holds the keys tokens are verified against, indexed by kid. Sets
+// loaded from a JWKS document can be reloaded so rotated keys are picked up
+// without a restart.
+type KeySet struct {
+	load   func(ctx context.Context) ([]byte, error)
+	logger *slog.Logger
+	// MinRefreshInterval rate limits the reloads triggered by tokens signed
+	// with an unknown kid.
+	MinRefreshInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]jwk
+	refreshed time.Time
+}
+
+type jwk struct {
+	alg string
+	key interface{}
+}
+
+// NewHMACKeySet verifies HS256 tokens with a shared secret.
+func NewHMACKeySet(secret []byte) *KeySet {
+	return &KeySet{keys: map[string]jwk{staticKeyID: {alg: "HS256", key: secret}}}
+}
+
+// NewJWKSFileKeySet loads a JWKS document from path; Refresh reads it again.
+func NewJWKSFileKeySet(ctx context.Context, path string, logger *slog.Logger) (*KeySet, error) {
+	return newJWKSKeySet(ctx, logger, func(ctx context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	})
+}
+
+// NewJWKSURLKeySet fetches a JWKS document from url; Refresh fetches it
+// again.
+func NewJWKSURLKeySet(ctx context.Context, url string, client *http.Client, logger *slog.Logger) (*KeySet, error) {
+	return newJWKSKeySet(ctx, logger, func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+		}
+
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	})
+}
+
+func newJWKSKeySet(ctx context.Context, logger *slog.Logger, load func(ctx context.Context) ([]byte, error)) (*KeySet, error) {
+	k := &KeySet{
+		load:               load,
+		logger:             logger,
+		MinRefreshInterval: time.Minute,
+	}
+
+	err := k.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Refresh reloads the JWKS document, keeping the current keys if it fails.
+func (k *KeySet) Refresh(ctx context.Context) error {
+	if k.load == nil {
+		return nil
+	}
+
+	k.mu.Lock()
+	k.refreshed = time.Now()
+	k.mu.Unlock()
+
+	return k.reload(ctx)
+}
+
+func (k *KeySet) reload(ctx context.Context) error {
+	data, err := k.load(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrKeySetUnavailable, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrKeySetUnavailable, err)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+
+	return nil
+}
+
+// RunRefresh reloads the key set every interval until ctx is cancelled.
+func (k *KeySet) RunRefresh(ctx context.Context, interval time.Duration) {
+	if k.load == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := k.Refresh(ctx)
+			if err != nil && ctx.Err() == nil {
+				k.logger.ErrorContext(ctx, "Error refreshing signing keys", "error", err)
+			}
+		}
+	}
+}
+
+// Key returns the key for kid, reloading the set once if kid is unknown
+// and the last reload is older than MinRefreshInterval.
+func (k *KeySet) Key(ctx context.Context, kid string) (string, interface{}, error) {
+	k.mu.Lock()
+	key, ok := k.lookup(kid)
+	stale := k.load != nil && time.Since(k.refreshed) >= k.MinRefreshInterval
+	if !ok && stale {
+		// Claim the reload so concurrent requests don't all trigger one.
+		k.refreshed = time.Now()
+	}
+	k.mu.Unlock()
+	if ok {
+		return key.alg, key.key, nil
+	}
+	if !stale {
+		return "", nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	err := k.reload(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	k.mu.RLock()
+	key, ok = k.lookup(kid)
+	k.mu.RUnlock()
+	if !ok {
+		return "", nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key.alg, key.key, nil
+}
+
+func (k *KeySet) lookup(kid string) (jwk, bool) {
+	if key, ok := k.keys[staticKeyID]; ok && k.load == nil {
+		return key, true
+	}
+
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+func parseJWKS(data []byte) (map[string]jwk, error) {
+	var document struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwks document: %w", err)
+	}
+
+	keys := make(map[string]jwk, len(document.Keys))
+	for _, raw := range document.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+
+		var key jwk
+		switch raw.Kty {
+		case "RSA":
+			key.alg = "RS256"
+			key.key, err = rsaPublicKey(raw.N, raw.E)
+		case "EC":
+			key.alg = "ES256"
+			key.key, err = ecdsaPublicKey(raw.Crv, raw.X, raw.Y)
+		case "oct":
+			key.alg = "HS256"
+			key.key, err = base64.RawURLEncoding.DecodeString(raw.K)
+		default:
+			continue
+		}
+		if err != nil || (raw.Alg != "" && raw.Alg != key.alg) {
+			// Providers publish keys for algorithms this service doesn't
+			// accept; skip them rather than rejecting the whole set.
+			continue
+		}
+
+		keys[raw.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks document has no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func rsaPublicKey(n string, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}
+	if key.N.BitLen() < 2048 || key.E < 3 {
+		return nil, errors.New("rsa key too weak")
+	}
+
+	return key, nil
+}
+
+func ecdsaPublicKey(crv string, x string, y string) (*ecdsa.PublicKey, error) {
+	if crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+
+	xBytes, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, err
+	}
+	yBytes, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("ec point is not on the curve")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTValidator verifies bearer tokens locally against a KeySet instead of
+// asking the user service about every request. Email lookups still go to
+// Users.
+type JWTValidator struct {
+	Keys     *KeySet
+	Users    port.UserPort
+	Issuer   string
+	Audience string
+	// Leeway absorbs clock skew between the issuer and this service when
+	// checking exp and nbf.
+	Leeway time.Duration
+
+	logger *slog.Logger
+}
+
+func NewJWTValidator(keys *KeySet, users port.UserPort, issuer string, audience string, logger *slog.Logger) *JWTValidator {
+	return &JWTValidator{
+		Keys:     keys,
+		Users:    users,
+		Issuer:   issuer,
+		Audience: audience,
+		Leeway:   30 * time.Second,
+		logger:   logger,
+	}
+}
+
+// claims are the registered claims plus the roles granted to the subject.
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// Authenticate verifies token and returns the principal named by its
+// subject. Failures to load the signing keys are entity.ErrAuthUnavailable;
+// anything wrong with the token itself is entity.ErrUnauthorized.
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	verified, err := v.verify(ctx, token)
+	if errors.Is(err, ErrKeySetUnavailable) {
+		return nil, fmt.Errorf("%w: %w", entity.ErrAuthUnavailable, err)
+	}
+	if err != nil {
+		v.logger.InfoContext(ctx, "Rejected token", "error", err)
+		return nil, entity.ErrUnauthorized
+	}
+
+	return &entity.Principal{OwnerId: verified.Subject, Roles: verified.Roles}, nil
+}
+
+func (v *JWTValidator) verify(ctx context.Context, token string) (*claims, error) {
+	token = strings.TrimSpace(token)
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	if token == "" {
+		return nil, errors.New("missing token")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.Leeway),
+	}
+	if v.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		options = append(options, jwt.WithAudience(v.Audience))
+	}
+
+	verified := &claims{}
+	_, err := jwt.ParseWithClaims(token, verified, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		alg, key, err := v.Keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// Pin the algorithm to the key so a token can't pick how its
+		// signature is checked.
+		if t.Method.Alg() != alg {
+			return nil, fmt.Errorf("key %q does not accept %s", kid, t.Method.Alg())
+		}
+
+		return key, nil
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if verified.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	return verified, nil
+}
+
+func (v *JWTValidator) GetEmail(ctx context.Context, ownerId string) (string, error) {
+	return v.Users.GetEmail(ctx, ownerId)
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.RegisteredClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "42",
+		Issuer:    "user-service",
+		Audience:  jwt.ClaimStrings{"video-service"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+// writeJWKS writes a JWKS document with the given public keys to a temp file.
+func writeJWKS(t *testing.T, path string, keys map[string]interface{}) {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	document := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			document.Keys = append(document.Keys, map[string]string{
+				"kid": kid, "kty": "RSA", "n": encode(key.N.Bytes()), "e": encode(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			document.Keys = append(document.Keys, map[string]string{
+				"kid": kid, "kty": "EC", "crv": "P-256", "x": encode(key.X.FillBytes(make([]byte, 32))), "y": encode(key.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("failed to encode jwks: %v", err)
+	}
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		t.Fatalf("failed to write jwks: %v", err)
+	}
+}
+
+func TestJWTValidator_HS256(t *testing.T) {
+	secret := []byte("a-shared-secret-of-reasonable-length")
+	validator := NewJWTValidator(NewHMACKeySet(secret), nil, "user-service", "video-service", slog.Default())
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	notYet := validClaims()
+	notYet.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"someone-else"}
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "attacker"
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid", "Bearer " + signToken(t, jwt.SigningMethodHS256, "", secret, validClaims()), true},
+		{"without bearer prefix", signToken(t, jwt.SigningMethodHS256, "", secret, validClaims()), true},
+		{"wrong secret", signToken(t, jwt.SigningMethodHS256, "", []byte("another-secret"), validClaims()), false},
+		{"expired", signToken(t, jwt.SigningMethodHS256, "", secret, expired), false},
+		{"not yet valid", signToken(t, jwt.SigningMethodHS256, "", secret, notYet), false},
+		{"wrong audience", signToken(t, jwt.SigningMethodHS256, "", secret, wrongAudience), false},
+		{"wrong issuer", signToken(t, jwt.SigningMethodHS256, "", secret, wrongIssuer), false},
+		{"no expiry", signToken(t, jwt.SigningMethodHS256, "", secret, noExpiry), false},
+		{"unsigned", signToken(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()), false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := validator.Authenticate(context.Background(), tt.token)
+			if !tt.valid {
+				if !errors.Is(err, entity.ErrUnauthorized) {
+					t.Errorf("Expected ErrUnauthorized, got %v %v", principal, err)
+				}
+				return
+			}
+			if err != nil || principal.OwnerId != "42" {
+				t.Errorf("Expected owner 42, got %v %v", principal, err)
+			}
+		})
+	}
+}
+
+func TestJWTValidator_JWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]interface{}{"rsa-1": &rsaKey.PublicKey})
+	keys, err := NewJWKSFileKeySet(context.Background(), path, slog.Default())
+	if err != nil {
+		t.Fatalf("Expected key set to load, got %v", err)
+	}
+	keys.MinRefreshInterval = 0
+	validator := NewJWTValidator(keys, nil, "", "", slog.Default())
+
+	principal, err := validator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()))
+	if err != nil || principal.OwnerId != "42" {
+		t.Errorf("Expected RS256 token to be valid, got %v %v", principal, err)
+	}
+
+	// An HS256 token "signed" with the RSA public key must not verify.
+	publicKey, _ := json.Marshal(rsaKey.PublicKey)
+	_, err = validator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodHS256, "rsa-1", publicKey, validClaims()))
+	if !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Expected algorithm confusion to be rejected, got %v", err)
+	}
+
+	// A rotated key is picked up on the first token that uses it.
+	writeJWKS(t, path, map[string]interface{}{"rsa-1": &rsaKey.PublicKey, "ec-1": &ecKey.PublicKey})
+	principal, err = validator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()))
+	if err != nil || principal.OwnerId != "42" {
+		t.Errorf("Expected ES256 token with rotated key to be valid, got %v %v", principal, err)
+	}
+
+	// Keys that can't be loaded are reported as an error, not a bad token.
+	os.Remove(path)
+	_, err = validator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodES256, "ec-2", ecKey, validClaims()))
+	if !errors.Is(err, ErrKeySetUnavailable) {
+		t.Errorf("Expected an unavailable key set error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const claimOutbox = `
+	UPDATE outbox SET locked_until = NOW() + $2 * INTERVAL '1 millisecond'
+	WHERE id IN (
+		SELECT id FROM outbox
+		WHERE published_at IS NULL AND dead_at IS NULL AND (locked_until IS NULL OR locked_until < NOW())
+		ORDER BY created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, video_id, event, payload, attempts, last_error, created_at, delivered_sinks`
+
+type OutboxRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewOutboxRepository(db *pgxpool.Pool, logger *slog.Logger) port.OutboxRepository {
+	return &OutboxRepository{db: db, logger: logger}
+}
+
+func (r *OutboxRepository) ClaimPending(limit int, lease time.Duration) ([]entity.OutboxMessage, error) {
+	messages := []entity.OutboxMessage{}
+	rows, err := r.db.Query(context.Background(), claimOutbox, limit, lease.Milliseconds())
+	if err != nil {
+		r.logger.Error("Error claiming outbox messages", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		message := entity.OutboxMessage{}
+		err = rows.Scan(&message.Id, &message.VideoId, &message.Event, &message.Payload, &message.Attempts, &message.LastError, &message.CreatedAt, &message.Delivered)
+		if err != nil {
+			r.logger.Error("Error scanning outbox message", "error", err)
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *OutboxRepository) MarkDelivered(id string, sink string) error {
+	_, err := r.db.Exec(context.Background(), "UPDATE outbox SET delivered_sinks = array_append(delivered_sinks, $1) WHERE id = $2 AND NOT $1 = ANY(delivered_sinks)", sink, id)
+	if err != nil {
+		r.logger.Error("Error marking outbox message as delivered", "sink", sink, "error", err)
+	}
+
+	return err
+}
+
+func (r *OutboxRepository) MarkPublished(id string) error {
+	_, err := r.db.Exec(context.Background(), "UPDATE outbox SET published_at = NOW(), locked_until = NULL WHERE id = $1", id)
+	if err != nil {
+		r.logger.Error("Error marking outbox message as published", "error", err)
+	}
+
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(id string, lastError string, retryIn time.Duration) error {
+	_, err := r.db.Exec(context.Background(), "UPDATE outbox SET attempts = attempts + 1, last_error = $1, locked_until = NOW() + $2 * INTERVAL '1 millisecond' WHERE id = $3",
+		lastError, retryIn.Milliseconds(), id)
+	if err != nil {
+		r.logger.Error("Error marking outbox message as failed", "error", err)
+	}
+
+	return err
+}
+
+func (r *OutboxRepository) MarkDead(id string, lastError string) error {
+	_, err := r.db.Exec(context.Background(), "UPDATE outbox SET attempts = attempts + 1, last_error = $1, dead_at = NOW(), locked_until = NULL WHERE id = $2", lastError, id)
+	if err != nil {
+		r.logger.Error("Error dead-lettering outbox message", "error", err)
+	}
+
+	return err
+}
+
+func (r *OutboxRepository) PrunePublished(before time.Time) (int64, error) {
+	tag, err := r.db.Exec(context.Background(), "DELETE FROM outbox WHERE published_at < $1", before)
+	if err != nil {
+		r.logger.Error("Error pruning outbox messages", "error", err)
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	videoEventsChannel = "video_events"
+	cancelChannel      = "video_cancel"
+)
+
+// PostgresBroker uses LISTEN/NOTIFY to broadcast relayed events and job
+// signals to every replica, so whichever pod holds the subscriber or runs the
+// job receives them.
+type PostgresBroker struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewPostgresBroker(db *pgxpool.Pool, logger *slog.Logger) *PostgresBroker {
+	return &PostgresBroker{db: db, logger: logger}
+}
+
+func (b *PostgresBroker) Notify(event entity.VideoEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.db.Exec(context.Background(), "SELECT pg_notify($1, $2)", videoEventsChannel, string(payload))
+	if err != nil {
+		b.logger.Error("Error publishing video event", "error", err)
+	}
+
+	return err
+}
+
+func (b *PostgresBroker) SignalCancel(videoId string) error {
+	_, err := b.db.Exec(context.Background(), "SELECT pg_notify($1, $2)", cancelChannel, videoId)
+	if err != nil {
+		b.logger.Error("Error signaling cancellation", "error", err)
+	}
+
+	return err
+}
+
+// Listen forwards every broadcast event to the sink until ctx is cancelled.
+func (b *PostgresBroker) Listen(ctx context.Context, sink port.VideoEventNotifier) {
+	b.subscribe(ctx, videoEventsChannel, func(payload string) {
+		var event entity.VideoEvent
+		err := json.Unmarshal([]byte(payload), &event)
+		if err != nil {
+			b.logger.ErrorContext(ctx, "Error decoding video event", "error", err)
+			return
+		}
+
+		sink.Notify(event)
+	})
+}
+
+// ListenCancellations calls abort with the id of every cancelled video until
+// ctx is cancelled.
+func (b *PostgresBroker) ListenCancellations(ctx context.Context, abort func(videoId string)) {
+	b.subscribe(ctx, cancelChannel, abort)
+}
+
+// subscribe reconnects whenever the listening connection is lost.
+func (b *PostgresBroker) subscribe(ctx context.Context, channel string, handle func(payload string)) {
+	for ctx.Err() == nil {
+		err := b.listen(ctx, channel, handle)
+		if err != nil && ctx.Err() == nil {
+			b.logger.ErrorContext(ctx, "Error listening on channel", "channel", channel, "error", err)
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context, channel string, handle func(payload string)) error {
+	conn, err := b.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		conn.Exec(context.Background(), "UNLISTEN *")
+		conn.Release()
+	}()
+
+	_, err = conn.Exec(ctx, "LISTEN "+channel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		handle(notification.Payload)
+	}
+}
@@ -2,67 +2,496 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
 	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	videoColumns = "id, owner_id, name, status, source_key, attempts, last_error, version, interval_seconds, source_bytes, created_at, finished_at"
+
+	claimStaleJobs = `
+		UPDATE videos SET worker_id = $1, heartbeat_at = NOW()
+		WHERE id IN (
+			SELECT id FROM videos
+			WHERE status = 'processing' AND heartbeat_at < NOW() - $2 * INTERVAL '1 millisecond'
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + videoColumns
+
+	findExpired = `
+		SELECT ` + videoColumns + ` FROM videos
+		WHERE status IN ('ready_to_download', 'error', 'cancelled', 'dead_letter')
+			AND ($1 = '' OR owner_id = $1) AND NOT (owner_id = ANY($2))
+			AND GREATEST(created_at, (SELECT MAX(a.created_at) FROM video_archives a WHERE a.video_id = videos.id)) < $3
+		ORDER BY created_at
+		LIMIT $4`
+
+	// videoFilterWhere matches entity.VideoFilter's fields other than its
+	// cursor, taking them as $1 to $4.
+	videoFilterWhere = `
+		WHERE ($1 = '' OR owner_id = $1) AND ($2 = '' OR status = $2)
+			AND ($3::timestamptz IS NULL OR created_at >= $3) AND ($4::timestamptz IS NULL OR created_at < $4)`
+
+	// finishedStatus sets finished_at for a status change to $1, keeping the
+	// original finish time when a finished video moves between terminal
+	// statuses, e.g. when it expires.
+	finishedStatus = "finished_at = CASE WHEN $1 = 'processing' THEN NULL ELSE COALESCE(finished_at, NOW()) END"
+)
+
 type PostgresRepository struct {
-	db *pgxpool.Pool
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
 }
 
-func NewPostgresRepository(db *pgxpool.Pool) port.VideoRepository {
-	return &PostgresRepository{db: db}
+// NewPostgresRepository bounds every call by timeout, on top of whatever
+// deadline the caller's context already carries.
+func NewPostgresRepository(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) port.VideoRepository {
+	return &PostgresRepository{db: db, logger: logger, timeout: timeout}
 }
 
-func (r *PostgresRepository) Save(video entity.VideoFile) error {
-	r.db.QueryRow(context.Background(), "INSERT INTO videos (id, owner_id, status) VALUES ($1, $2, $3)", video.Id, video.OwnerId, video.Status)
+func (r *PostgresRepository) Save(ctx context.Context, video entity.VideoFile, workerId string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	return nil
+	_, err := r.db.Exec(ctx, "INSERT INTO videos ("+videoColumns+", worker_id, heartbeat_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())",
+		video.Id, video.OwnerId, video.GetOriginalName(), video.Status, video.SourceKey, video.Attempts, video.LastError, video.Version, video.IntervalSeconds, video.SourceSize,
+		video.CreatedAt, video.FinishedAt, workerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving video", logging.VideoID, video.Id, "error", err)
+	}
+
+	return err
 }
 
-func (r *PostgresRepository) FindById(id string) (*entity.VideoFile, error) {
-	video := entity.VideoFile{}
-	row := r.db.QueryRow(context.Background(), "SELECT id, owner_id, status FROM videos WHERE id = $1", id)
-	err := row.Scan(&video.Id, &video.OwnerId, &video.Status)
+func (r *PostgresRepository) FindById(ctx context.Context, id string) (*entity.VideoFile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRow(ctx, "SELECT "+videoColumns+" FROM videos WHERE id = $1", id)
+	video, err := scanVideo(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrVideoNotFound
+	}
 	if err != nil {
-		fmt.Println("Error scanning video", err)
+		r.logger.ErrorContext(ctx, "Error scanning video", logging.VideoID, id, "error", err)
 		return nil, err
 	}
 
-	return &video, nil
+	return video, nil
 }
 
-func (r *PostgresRepository) FindByOwnerId(ownerId string) ([]entity.VideoFile, error) {
+func (r *PostgresRepository) FindByOwnerId(ctx context.Context, ownerId string) ([]entity.VideoFile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	videos := []entity.VideoFile{}
-	rows, err := r.db.Query(context.Background(), "SELECT id, owner_id, status FROM videos WHERE owner_id = $1", ownerId)
+	rows, err := r.db.Query(ctx, "SELECT "+videoColumns+" FROM videos WHERE owner_id = $1", ownerId)
 	if err != nil {
-		fmt.Println("Error querying videos", err)
+		r.logger.ErrorContext(ctx, "Error querying videos", "error", err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		video := entity.VideoFile{}
-		err = rows.Scan(&video.Id, &video.OwnerId, &video.Status)
+		video, err := scanVideo(rows)
 		if err != nil {
-			fmt.Println("Error scanning video", err)
+			r.logger.ErrorContext(ctx, "Error scanning video", "error", err)
 			return nil, err
 		}
 
-		videos = append(videos, video)
+		videos = append(videos, *video)
 	}
 
 	return videos, nil
 }
 
-func (r *PostgresRepository) UpdateStatus(id string, status string) error {
-	_, err := r.db.Exec(context.Background(), "UPDATE videos SET status = $1 WHERE id = $2", status, id)
+// FindAll pages through the matching videos by keyset: each page starts
+// after the sort key and id of the previous page's last video, so it stays
+// cheap however deep the listing goes.
+func (r *PostgresRepository) FindAll(ctx context.Context, filter entity.VideoFilter) ([]entity.VideoFile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	sortKey := "created_at"
+	if filter.Sort == entity.SortFinishedAt {
+		sortKey = fmt.Sprintf("COALESCE(finished_at, '%s')", entity.UnfinishedSortKey.Format(time.RFC3339))
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	var afterKey *time.Time
+	var afterId string
+	if filter.After != nil {
+		afterKey, afterId = &filter.After.Key, filter.After.Id
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM videos %s AND ($5::timestamptz IS NULL OR (%s, id) %s ($5, $6)) ORDER BY %[3]s %[5]s, id %[5]s LIMIT $7",
+		videoColumns, videoFilterWhere, sortKey, comparison, direction)
+
+	videos := []entity.VideoFile{}
+	rows, err := r.db.Query(ctx, query, filter.OwnerId, filter.Status, optionalTime(filter.CreatedAfter), optionalTime(filter.CreatedBefore), afterKey, afterId, filter.Limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying videos", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		video, err := scanVideo(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning video", "error", err)
+			return nil, err
+		}
+
+		videos = append(videos, *video)
+	}
+
+	return videos, rows.Err()
+}
+
+func (r *PostgresRepository) CountAll(ctx context.Context, filter entity.VideoFilter) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM videos "+videoFilterWhere,
+		filter.OwnerId, filter.Status, optionalTime(filter.CreatedAfter), optionalTime(filter.CreatedBefore)).Scan(&count)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error counting videos", "error", err)
+	}
+
+	return count, err
+}
+
+func (r *PostgresRepository) FindExpired(ctx context.Context, ownerId string, exceptOwnerIds []string, before time.Time, limit int) ([]entity.VideoFile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if exceptOwnerIds == nil {
+		// A NULL array would exclude every owner.
+		exceptOwnerIds = []string{}
+	}
+
+	videos := []entity.VideoFile{}
+	rows, err := r.db.Query(ctx, findExpired, ownerId, exceptOwnerIds, before, limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying expired videos", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		video, err := scanVideo(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning video", "error", err)
+			return nil, err
+		}
+
+		videos = append(videos, *video)
+	}
+
+	return videos, rows.Err()
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "DELETE FROM videos WHERE id = $1", id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error deleting video", logging.VideoID, id, "error", err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) CountByStatus(ctx context.Context, status string) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM videos WHERE status = $1", status).Scan(&count)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error counting videos", "error", err)
+	}
+
+	return count, err
+}
+
+func (r *PostgresRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE videos SET status = $1, "+finishedStatus+" WHERE id = $2", status, id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating video status", logging.VideoID, id, "error", err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) RecordAttempt(ctx context.Context, id string, attempts int, lastError string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE videos SET attempts = $1, last_error = $2 WHERE id = $3", attempts, lastError, id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error recording video attempt", logging.VideoID, id, "error", err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) ClaimJob(ctx context.Context, video entity.VideoFile, workerId string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE videos SET worker_id = $1, heartbeat_at = NOW(), version = $2, interval_seconds = $3 WHERE id = $4",
+		workerId, video.Version, video.IntervalSeconds, video.Id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error claiming video job", logging.VideoID, video.Id, "error", err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) Heartbeat(ctx context.Context, workerId string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE videos SET heartbeat_at = NOW() WHERE worker_id = $1 AND status = 'processing'", workerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating job heartbeat", "error", err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) ReleaseJobs(ctx context.Context, workerId string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE videos SET worker_id = '', heartbeat_at = '-infinity' WHERE worker_id = $1 AND status = 'processing'", workerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error releasing jobs", "error", err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) ClaimStaleJobs(ctx context.Context, workerId string, staleAfter time.Duration, limit int) ([]entity.VideoFile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	videos := []entity.VideoFile{}
+	rows, err := r.db.Query(ctx, claimStaleJobs, workerId, staleAfter.Milliseconds(), limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error claiming stale jobs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		video, err := scanVideo(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning video", "error", err)
+			return nil, err
+		}
+
+		videos = append(videos, *video)
+	}
+
+	return videos, rows.Err()
+}
+
+func (r *PostgresRepository) TransitionStatus(ctx context.Context, event entity.VideoEvent, from string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error starting transaction", logging.VideoID, event.VideoId, "error", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE videos SET status = $1, "+finishedStatus+" WHERE id = $2 AND status = $3", event.Status, event.VideoId, from)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating video status", logging.VideoID, event.VideoId, "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrStatusConflict
+	}
+
+	err = r.insertEvent(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) CompleteProcessing(ctx context.Context, archive entity.VideoArchive, event entity.VideoEvent) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error starting transaction", logging.VideoID, event.VideoId, "error", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE videos SET status = $1, "+finishedStatus+" WHERE id = $2 AND status = 'processing'", event.Status, event.VideoId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating video status", logging.VideoID, event.VideoId, "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrStatusConflict
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO video_archives (video_id, version, key, frame_count, interval_seconds, size_bytes, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		archive.VideoId, archive.Version, archive.Key, archive.FrameCount, archive.IntervalSeconds, archive.Size, archive.CreatedAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving video archive", logging.VideoID, event.VideoId, "error", err)
+		return err
+	}
+
+	err = r.insertEvent(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) FindArchives(ctx context.Context, videoId string) ([]entity.VideoArchive, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	archives := []entity.VideoArchive{}
+	rows, err := r.db.Query(ctx, "SELECT video_id, version, key, frame_count, interval_seconds, size_bytes, created_at FROM video_archives WHERE video_id = $1 ORDER BY version", videoId)
 	if err != nil {
-		fmt.Println("Error updating video status", err)
+		r.logger.ErrorContext(ctx, "Error querying video archives", logging.VideoID, videoId, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		archive := entity.VideoArchive{}
+		err = rows.Scan(&archive.VideoId, &archive.Version, &archive.Key, &archive.FrameCount, &archive.IntervalSeconds, &archive.Size, &archive.CreatedAt)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning video archive", logging.VideoID, videoId, "error", err)
+			return nil, err
+		}
+
+		archives = append(archives, archive)
+	}
+
+	return archives, nil
+}
+
+func (r *PostgresRepository) FindEvents(ctx context.Context, ownerId string) ([]entity.VideoEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	events := []entity.VideoEvent{}
+	rows, err := r.db.Query(ctx, "SELECT payload FROM video_status_history WHERE owner_id = $1 ORDER BY occurred_at", ownerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying video events", logging.OwnerID, ownerId, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var payload []byte
+		err = rows.Scan(&payload)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning video event", logging.OwnerID, ownerId, "error", err)
+			return nil, err
+		}
+
+		event := entity.VideoEvent{}
+		err = json.Unmarshal(payload, &event)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error decoding video event", logging.OwnerID, ownerId, "error", err)
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *PostgresRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, r.timeout)
+}
+
+// withTimeout bounds ctx by timeout; zero leaves it as is.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// insertEvent records a status change in the video's history and in the
+// outbox, as part of the transaction making the change.
+func (r *PostgresRepository) insertEvent(ctx context.Context, tx pgx.Tx, event entity.VideoEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO video_status_history (id, video_id, owner_id, status, payload, occurred_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		event.Id, event.VideoId, event.OwnerId, event.Status, payload, event.OccurredAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error writing video status history", logging.VideoID, event.VideoId, "error", err)
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO outbox (id, video_id, event, payload, created_at) VALUES ($1, $2, $3, $4, $5)",
+		event.Id, event.VideoId, event.Event, payload, event.OccurredAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error writing outbox message", logging.VideoID, event.VideoId, "error", err)
 	}
 
 	return err
 }
+
+// optionalTime passes the zero time to queries as NULL.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func scanVideo(row pgx.Row) (*entity.VideoFile, error) {
+	video := entity.VideoFile{}
+	err := row.Scan(&video.Id, &video.OwnerId, &video.Name, &video.Status, &video.SourceKey, &video.Attempts, &video.LastError, &video.Version, &video.IntervalSeconds, &video.SourceSize,
+		&video.CreatedAt, &video.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &video, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRateLimiter keeps token buckets in Postgres so every replica
+// draws from the same ones. Each bucket is stored as the generic cell rate
+// algorithm's theoretical arrival time, which behaves like a token bucket
+// but can be checked and updated in a single statement against the
+// database clock.
+type PostgresRateLimiter struct {
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewPostgresRateLimiter(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) *PostgresRateLimiter {
+	return &PostgresRateLimiter{db: db, logger: logger, timeout: timeout}
+}
+
+func (r *PostgresRateLimiter) Allow(ctx context.Context, key string, limit entity.RateLimit) (bool, time.Duration, error) {
+	if !limit.Enabled() {
+		return true, 0, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Each request pushes the arrival time forward by one emission
+	// interval; it is allowed as long as that stays within a period of now.
+	interval := (limit.Period / time.Duration(limit.Requests)).Microseconds()
+	tolerance := limit.Period.Microseconds()
+
+	var tat time.Time
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO rate_limits (key, tat) VALUES ($1, NOW() + $2 * INTERVAL '1 microsecond')
+		ON CONFLICT (key) DO UPDATE SET tat = GREATEST(rate_limits.tat, NOW()) + $2 * INTERVAL '1 microsecond'
+		WHERE GREATEST(rate_limits.tat, NOW()) + $2 * INTERVAL '1 microsecond' <= NOW() + $3 * INTERVAL '1 microsecond'
+		RETURNING tat`, key, interval, tolerance).Scan(&tat)
+	if err == nil {
+		return true, 0, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		r.logger.ErrorContext(ctx, "Error taking rate limit token", "key", key, "error", err)
+		return false, 0, err
+	}
+
+	var wait float64
+	err = r.db.QueryRow(ctx, `
+		SELECT EXTRACT(EPOCH FROM (GREATEST(tat, NOW()) + $2 * INTERVAL '1 microsecond' - $3 * INTERVAL '1 microsecond' - NOW()))
+		FROM rate_limits WHERE key = $1`, key, interval, tolerance).Scan(&wait)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error reading rate limit", "key", key, "error", err)
+		return false, 0, err
+	}
+
+	return false, time.Duration(math.Max(wait, 0) * float64(time.Second)), nil
+}
+
+// Prune deletes buckets that have refilled completely; a missing bucket is
+// equivalent to a full one.
+func (r *PostgresRateLimiter) Prune(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "DELETE FROM rate_limits WHERE tat < NOW()")
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error pruning rate limits", "error", err)
+	}
+
+	return err
+}
+
+// RunPrune prunes every interval until ctx is cancelled.
+func (r *PostgresRateLimiter) RunPrune(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Prune(ctx)
+		}
+	}
+}
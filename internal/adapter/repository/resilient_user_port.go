@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+const maxCachedPrincipals = 10000
+
+// ResilientUserPort decorates a UserPort backed by a remote service so a
+// slow or failing dependency degrades into fast 503s instead of hanging
+// every request: tokens are cached for a short TTL, each call is bounded by
+// Timeout, failed calls are retried and repeated failures open a circuit
+// breaker. Authenticate and GetEmail trip separate breakers, so email
+// lookups for notifications can't lock everyone out.
+type ResilientUserPort struct {
+	Next port.UserPort
+	// CacheTTL is how long a validated token is trusted without asking
+	// Next again. Only successful lookups are cached.
+	CacheTTL time.Duration
+	// Timeout bounds each attempt; zero leaves it to Next.
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+	// FailureThreshold consecutive failures open the breaker for
+	// OpenDuration, after which a single call is let through to probe.
+	FailureThreshold int
+	OpenDuration     time.Duration
+
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedPrincipal
+	auth  breaker
+	email breaker
+}
+
+// breaker is the state of one circuit breaker, guarded by
+// ResilientUserPort.mu.
+type breaker struct {
+	name      string
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+type cachedPrincipal struct {
+	principal entity.Principal
+	expiresAt time.Time
+}
+
+func NewResilientUserPort(next port.UserPort, logger *slog.Logger) *ResilientUserPort {
+	return &ResilientUserPort{
+		Next:             next,
+		CacheTTL:         30 * time.Second,
+		Timeout:          2 * time.Second,
+		Retries:          2,
+		RetryDelay:       100 * time.Millisecond,
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+		logger:           logger,
+		now:              time.Now,
+		cache:            make(map[[sha256.Size]byte]cachedPrincipal),
+		auth:             breaker{name: "authenticate"},
+		email:            breaker{name: "get_email"},
+	}
+}
+
+func (u *ResilientUserPort) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	// Tokens are cached by hash so the raw credentials don't sit in memory.
+	key := sha256.Sum256([]byte(token))
+	if principal, ok := u.cached(key); ok {
+		return principal, nil
+	}
+
+	var principal *entity.Principal
+	err := u.call(ctx, &u.auth, func(ctx context.Context) error {
+		var err error
+		principal, err = u.Next.Authenticate(ctx, token)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.store(key, *principal)
+	return principal, nil
+}
+
+func (u *ResilientUserPort) GetEmail(ctx context.Context, ownerId string) (string, error) {
+	var email string
+	err := u.call(ctx, &u.email, func(ctx context.Context) error {
+		var err error
+		email, err = u.Next.GetEmail(ctx, ownerId)
+		return err
+	})
+
+	return email, err
+}
+
+// call runs fn through the breaker, retrying service failures. Those are
+// reported wrapped in entity.ErrAuthUnavailable; any other error is the
+// service's answer and is returned as is.
+func (u *ResilientUserPort) call(ctx context.Context, b *breaker, fn func(ctx context.Context) error) error {
+	if !u.allow(b) {
+		return fmt.Errorf("%w: user service circuit open", entity.ErrAuthUnavailable)
+	}
+
+	var err error
+	for attempt := 0; attempt <= u.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				u.record(ctx, b, err)
+				return fmt.Errorf("%w: %w", entity.ErrAuthUnavailable, ctx.Err())
+			case <-time.After(u.RetryDelay << (attempt - 1)):
+			}
+		}
+
+		err = u.attempt(ctx, fn)
+		if err == nil || !serviceFailure(err) || ctx.Err() != nil {
+			break
+		}
+		u.logger.WarnContext(ctx, "User service call failed", "attempt", attempt+1, "error", err)
+	}
+
+	u.record(ctx, b, err)
+	if err != nil && serviceFailure(err) {
+		return fmt.Errorf("%w: %w", entity.ErrAuthUnavailable, err)
+	}
+
+	return err
+}
+
+func (u *ResilientUserPort) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if u.Timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
+	defer cancel()
+
+	return fn(ctx)
+}
+
+// allow reports whether a call may go through: always while the breaker is
+// closed, once per OpenDuration while it is open.
+func (u *ResilientUserPort) allow(b *breaker) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if b.failures < u.FailureThreshold {
+		return true
+	}
+	if b.probing || u.now().Before(b.openUntil) {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+func (u *ResilientUserPort) record(ctx context.Context, b *breaker, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	b.probing = false
+	if err == nil || !serviceFailure(err) {
+		// A rejected token or unknown owner still means the service answered.
+		b.failures = 0
+		return
+	}
+	if ctx.Err() != nil {
+		// The caller gave up; that says nothing about the service.
+		return
+	}
+
+	b.failures++
+	if b.failures >= u.FailureThreshold {
+		if b.failures == u.FailureThreshold {
+			u.logger.Error("User service circuit opened", "call", b.name, "failures", b.failures, "error", err)
+		}
+		b.openUntil = u.now().Add(u.OpenDuration)
+	}
+}
+
+// serviceFailure reports whether err means the user service is unhealthy:
+// transport errors and 5xx responses count, answers about the token or
+// owner asked for don't.
+func serviceFailure(err error) bool {
+	var status statusError
+	if errors.As(err, &status) {
+		return status.code >= 500
+	}
+
+	return !errors.Is(err, entity.ErrUnauthorized) && !errors.Is(err, entity.ErrEmailNotFound)
+}
+
+func (u *ResilientUserPort) cached(key [sha256.Size]byte) (*entity.Principal, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.cache[key]
+	if !ok || !u.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+
+	principal := entry.principal
+	return &principal, true
+}
+
+func (u *ResilientUserPort) store(key [sha256.Size]byte, principal entity.Principal) {
+	if u.CacheTTL <= 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+	if len(u.cache) >= maxCachedPrincipals {
+		for k, entry := range u.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(u.cache, k)
+			}
+		}
+	}
+	if len(u.cache) >= maxCachedPrincipals {
+		// Still full of live entries: start over rather than grow unbounded.
+		clear(u.cache)
+	}
+
+	u.cache[key] = cachedPrincipal{principal: principal, expiresAt: now.Add(u.CacheTTL)}
+}
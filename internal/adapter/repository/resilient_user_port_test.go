@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// flakyUserPort fails with errs in order, then authenticates every token as
+// owner 123. Email lookups fail with emailErr when it is set.
+type flakyUserPort struct {
+	errs       []error
+	calls      int
+	emailErr   error
+	emailCalls int
+}
+
+func (f *flakyUserPort) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &entity.Principal{OwnerId: "123"}, nil
+}
+
+func (f *flakyUserPort) GetEmail(ctx context.Context, ownerId string) (string, error) {
+	f.emailCalls++
+	if f.emailErr != nil {
+		return "", f.emailErr
+	}
+	return "user@example.com", nil
+}
+
+func newTestResilientUserPort(next *flakyUserPort) *ResilientUserPort {
+	users := NewResilientUserPort(next, slog.Default())
+	users.RetryDelay = time.Millisecond
+	return users
+}
+
+func TestResilientUserPort_CachesPrincipals(t *testing.T) {
+	next := &flakyUserPort{}
+	users := newTestResilientUserPort(next)
+	now := time.Now()
+	users.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		principal, err := users.Authenticate(context.Background(), "Bearer token")
+		if err != nil || principal.OwnerId != "123" {
+			t.Fatalf("Expected owner 123, got %v %v", principal, err)
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected a single call while cached, got %d", next.calls)
+	}
+
+	now = now.Add(users.CacheTTL)
+	users.Authenticate(context.Background(), "Bearer token")
+	if next.calls != 2 {
+		t.Errorf("Expected the expired entry to be looked up again, got %d calls", next.calls)
+	}
+}
+
+func TestResilientUserPort_RetriesOnlyServiceFailures(t *testing.T) {
+	next := &flakyUserPort{errs: []error{errors.New("connection reset")}}
+	users := newTestResilientUserPort(next)
+
+	_, err := users.Authenticate(context.Background(), "Bearer token")
+	if err != nil || next.calls != 2 {
+		t.Errorf("Expected a retry to succeed, got %v after %d calls", err, next.calls)
+	}
+
+	next = &flakyUserPort{errs: []error{entity.ErrUnauthorized, entity.ErrUnauthorized}}
+	users = newTestResilientUserPort(next)
+
+	for i := 0; i < 2; i++ {
+		_, err = users.Authenticate(context.Background(), "Bearer forged")
+		if !errors.Is(err, entity.ErrUnauthorized) {
+			t.Errorf("Expected ErrUnauthorized, got %v", err)
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("Expected rejected tokens to be neither retried nor cached, got %d calls", next.calls)
+	}
+}
+
+func TestResilientUserPort_CircuitBreaker(t *testing.T) {
+	outage := errors.New("service unavailable")
+	next := &flakyUserPort{errs: []error{outage, outage, outage, outage}}
+	users := newTestResilientUserPort(next)
+	users.Retries = 0
+	users.FailureThreshold = 2
+	now := time.Now()
+	users.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := users.Authenticate(context.Background(), "Bearer token")
+		if !errors.Is(err, entity.ErrAuthUnavailable) {
+			t.Errorf("Expected ErrAuthUnavailable, got %v", err)
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("Expected the open breaker to short-circuit, got %d calls", next.calls)
+	}
+
+	// After OpenDuration a probe goes through; it fails and reopens.
+	now = now.Add(users.OpenDuration)
+	users.Authenticate(context.Background(), "Bearer token")
+	users.Authenticate(context.Background(), "Bearer token")
+	if next.calls != 3 {
+		t.Errorf("Expected a single probe, got %d calls", next.calls)
+	}
+
+	// The next probe succeeds and closes the breaker.
+	next.errs = nil
+	now = now.Add(users.OpenDuration)
+	_, err := users.Authenticate(context.Background(), "Bearer token")
+	if err != nil {
+		t.Fatalf("Expected the probe to succeed, got %v", err)
+	}
+	_, err = users.Authenticate(context.Background(), "Bearer other")
+	if err != nil || next.calls != 5 {
+		t.Errorf("Expected the breaker to be closed, got %v after %d calls", err, next.calls)
+	}
+}
+
+func TestResilientUserPort_EmailLookupsHaveTheirOwnBreaker(t *testing.T) {
+	next := &flakyUserPort{}
+	users := newTestResilientUserPort(next)
+	users.Retries = 0
+	users.FailureThreshold = 2
+
+	// Unknown owners and client errors are answers, not failures.
+	for _, answer := range []error{entity.ErrEmailNotFound, statusError{code: 400}} {
+		next.emailErr = answer
+		for i := 0; i < 3; i++ {
+			_, err := users.GetEmail(context.Background(), "unknown")
+			if !errors.Is(err, answer) {
+				t.Errorf("Expected %v, got %v", answer, err)
+			}
+		}
+	}
+	if next.emailCalls != 6 {
+		t.Errorf("Expected the breaker to stay closed, got %d calls", next.emailCalls)
+	}
+
+	next.emailErr = statusError{code: 503}
+	for i := 0; i < 3; i++ {
+		_, err := users.GetEmail(context.Background(), "123")
+		if !errors.Is(err, entity.ErrAuthUnavailable) {
+			t.Errorf("Expected ErrAuthUnavailable, got %v", err)
+		}
+	}
+	if next.emailCalls != 8 {
+		t.Errorf("Expected the email breaker to open, got %d calls", next.emailCalls)
+	}
+
+	_, err := users.Authenticate(context.Background(), "Bearer token")
+	if err != nil {
+		t.Errorf("Expected authentication to be unaffected, got %v", err)
+	}
+}
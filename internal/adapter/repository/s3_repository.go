@@ -2,9 +2,9 @@ package repository
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -12,22 +12,31 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/gomesmatheus/tc-hackaton/internal/adapter/repository")
+
 type S3Repository struct {
+	client     *s3.S3
 	uploader   *s3manager.Uploader
 	downloader *s3manager.Downloader
 	bucketName string
+	logger     *slog.Logger
 }
 
-func NewS3Repository(bucketName string) *S3Repository {
+func NewS3Repository(bucketName string, logger *slog.Logger) *S3Repository {
 	awsAccessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
 	awsSecretAccessKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
 	awsRegion := os.Getenv("AWS_REGION")
 	token := os.Getenv("AWS_SESSION_TOKEN")
 
 	if awsAccessKeyID == "" || awsSecretAccessKey == "" || awsRegion == "" || token == "" {
-		log.Fatal("Missing required AWS environment variables")
+		logger.Error("Missing required AWS environment variables")
+		os.Exit(1)
 	}
 
 	sess, err := session.NewSession(&aws.Config{
@@ -35,36 +44,77 @@ func NewS3Repository(bucketName string) *S3Repository {
 		Credentials: credentials.NewStaticCredentials(awsAccessKeyID, awsSecretAccessKey, token),
 	})
 	if err != nil {
-		log.Fatalf("Failed to create AWS session: %v", err)
+		logger.Error("Failed to create AWS session", "error", err)
+		os.Exit(1)
 	}
 
 	return &S3Repository{
+		client:     s3.New(sess),
 		uploader:   s3manager.NewUploader(sess),
 		downloader: s3manager.NewDownloader(sess),
 		bucketName: bucketName,
+		logger:     logger,
 	}
 }
 
-func (r *S3Repository) UploadFile(key string, file io.Reader) error {
-	_, err := r.uploader.Upload(&s3manager.UploadInput{
+func (r *S3Repository) UploadFile(ctx context.Context, key string, file io.Reader) error {
+	ctx, span := r.startSpan(ctx, "s3.PutObject", key)
+	defer span.End()
+
+	_, err := r.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 		Bucket: aws.String(r.bucketName),
 		Key:    aws.String(key),
 		Body:   file,
 	})
+	recordError(span, err)
 	return err
 }
 
-func (r *S3Repository) DownloadFile(key string) (io.Reader, error) {
+func (r *S3Repository) DownloadFile(ctx context.Context, key string) (io.Reader, error) {
+	ctx, span := r.startSpan(ctx, "s3.GetObject", key)
+	defer span.End()
+
 	buf := aws.NewWriteAtBuffer([]byte{})
 
-	_, err := r.downloader.Download(buf, &s3.GetObjectInput{
+	_, err := r.downloader.DownloadWithContext(ctx, buf, &s3.GetObjectInput{
 		Bucket: aws.String(r.bucketName),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		fmt.Println("Error downloading file", err)
+		r.logger.ErrorContext(ctx, "Error downloading file", "error", err)
+		recordError(span, err)
 		return nil, err
 	}
 
 	return bytes.NewReader(buf.Bytes()), nil
 }
+
+func (r *S3Repository) Delete(ctx context.Context, key string) error {
+	ctx, span := r.startSpan(ctx, "s3.DeleteObject", key)
+	defer span.End()
+
+	_, err := r.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(r.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error deleting file", "error", err)
+		recordError(span, err)
+	}
+
+	return err
+}
+
+func (r *S3Repository) startSpan(ctx context.Context, name string, key string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("aws.s3.bucket", r.bucketName),
+		attribute.String("aws.s3.key", key),
+	))
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	shareGrantColumns = "id, video_id, owner_id, grantee_id, permission, created_at, revoked_at"
+	shareLinkColumns  = "id, video_id, owner_id, version, expires_at, max_downloads, downloads, created_at, revoked_at"
+)
+
+type ShareRepository struct {
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewShareRepository(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) port.ShareRepository {
+	return &ShareRepository{db: db, logger: logger, timeout: timeout}
+}
+
+func (r *ShareRepository) SaveGrant(ctx context.Context, grant entity.ShareGrant) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO share_grants (`+shareGrantColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (video_id, grantee_id) WHERE revoked_at IS NULL DO UPDATE SET permission = EXCLUDED.permission`,
+		grant.Id, grant.VideoId, grant.OwnerId, grant.GranteeId, grant.Permission, grant.CreatedAt, grant.RevokedAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving share grant", "error", err)
+	}
+
+	return err
+}
+
+func (r *ShareRepository) FindGrant(ctx context.Context, videoId string, granteeId string) (*entity.ShareGrant, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	grant, err := scanShareGrant(r.db.QueryRow(ctx, "SELECT "+shareGrantColumns+" FROM share_grants WHERE video_id = $1 AND grantee_id = $2 AND revoked_at IS NULL", videoId, granteeId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrShareNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error scanning share grant", "error", err)
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+func (r *ShareRepository) FindGrantsByVideoId(ctx context.Context, videoId string) ([]entity.ShareGrant, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	grants := []entity.ShareGrant{}
+	rows, err := r.db.Query(ctx, "SELECT "+shareGrantColumns+" FROM share_grants WHERE video_id = $1 ORDER BY created_at", videoId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying share grants", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		grant, err := scanShareGrant(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning share grant", "error", err)
+			return nil, err
+		}
+
+		grants = append(grants, *grant)
+	}
+
+	return grants, rows.Err()
+}
+
+func (r *ShareRepository) RevokeGrant(ctx context.Context, videoId string, id string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, "UPDATE share_grants SET revoked_at = $1 WHERE id = $2 AND video_id = $3 AND revoked_at IS NULL", at, id, videoId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error revoking share grant", "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (r *ShareRepository) SaveLink(ctx context.Context, link entity.ShareLink) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "INSERT INTO share_links ("+shareLinkColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		link.Id, link.VideoId, link.OwnerId, link.Version, link.ExpiresAt, link.MaxDownloads, link.Downloads, link.CreatedAt, link.RevokedAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving share link", "error", err)
+	}
+
+	return err
+}
+
+func (r *ShareRepository) FindLinksByVideoId(ctx context.Context, videoId string) ([]entity.ShareLink, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	links := []entity.ShareLink{}
+	rows, err := r.db.Query(ctx, "SELECT "+shareLinkColumns+" FROM share_links WHERE video_id = $1 ORDER BY created_at", videoId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying share links", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Error scanning share link", "error", err)
+			return nil, err
+		}
+
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *ShareRepository) RevokeLink(ctx context.Context, videoId string, id string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, "UPDATE share_links SET revoked_at = $1 WHERE id = $2 AND video_id = $3 AND revoked_at IS NULL", at, id, videoId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error revoking share link", "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrShareNotFound
+	}
+
+	return nil
+}
+
+// ConsumeLink checks and counts the download in a single statement so
+// concurrent downloads can't go over the limit.
+func (r *ShareRepository) ConsumeLink(ctx context.Context, id string, now time.Time) (*entity.ShareLink, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	link, err := scanShareLink(r.db.QueryRow(ctx, `
+		UPDATE share_links SET downloads = downloads + 1
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > $2 AND (max_downloads = 0 OR downloads < max_downloads)
+		RETURNING `+shareLinkColumns, id, now))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrShareLinkUnavailable
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error consuming share link", "error", err)
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (r *ShareRepository) ReleaseLink(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "UPDATE share_links SET downloads = downloads - 1 WHERE id = $1 AND downloads > 0", id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error releasing share link", "error", err)
+	}
+
+	return err
+}
+
+func scanShareGrant(row pgx.Row) (*entity.ShareGrant, error) {
+	grant := entity.ShareGrant{}
+	err := row.Scan(&grant.Id, &grant.VideoId, &grant.OwnerId, &grant.GranteeId, &grant.Permission, &grant.CreatedAt, &grant.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grant, nil
+}
+
+func scanShareLink(row pgx.Row) (*entity.ShareLink, error) {
+	link := entity.ShareLink{}
+	err := row.Scan(&link.Id, &link.VideoId, &link.OwnerId, &link.Version, &link.ExpiresAt, &link.MaxDownloads, &link.Downloads, &link.CreatedAt, &link.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
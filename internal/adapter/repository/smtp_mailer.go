@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPMailer struct {
+	addr   string
+	auth   smtp.Auth
+	from   string
+	logger *slog.Logger
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string, logger *slog.Logger) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr:   net.JoinHostPort(host, port),
+		auth:   auth,
+		from:   from,
+		logger: logger,
+	}
+}
+
+func (m *SMTPMailer) Send(to string, subject string, body string) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", headerValue(to))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(subject)))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg.String()))
+	if err != nil {
+		m.logger.Error("Error sending email", "error", err)
+	}
+
+	return err
+}
+
+// headerValue drops line breaks so a value can't start a header of its own.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+)
+
+// startSMTPStandIn accepts a single SMTP session and sends the received
+// DATA section on the returned channel.
+func startSMTPStandIn(t *testing.T) (string, string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP stand-in")
+
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "DATA"):
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				messages <- data.String()
+				reply("250 OK")
+			case strings.HasPrefix(command, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, messages
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	host, port, messages := startSMTPStandIn(t)
+	mailer := NewSMTPMailer(host, port, "", "", "noreply@example.com", slog.Default())
+
+	err := mailer.Send("user@example.com", "Your frames are ready", "Hello,\nDownload it here")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	message := <-messages
+	for _, expected := range []string{"To: user@example.com", "Subject: Your frames are ready", "Hello,\r\nDownload it here"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected message to contain %q, got %q", expected, message)
+		}
+	}
+}
+
+func TestSMTPMailer_SendEncodesSubject(t *testing.T) {
+	host, port, messages := startSMTPStandIn(t)
+	mailer := NewSMTPMailer(host, port, "", "", "noreply@example.com", slog.Default())
+
+	err := mailer.Send("user@example.com", "Frames for café.mp4\r\nBcc: victim@example.com", "Hello")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	message := <-messages
+	if strings.Contains(message, "\r\nBcc:") {
+		t.Errorf("expected the subject not to add headers, got %q", message)
+	}
+	if !strings.Contains(message, "Subject: =?utf-8?q?") {
+		t.Errorf("expected the subject to be encoded, got %q", message)
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UsageRepository struct {
+	db      *pgxpool.Pool
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewUsageRepository(db *pgxpool.Pool, logger *slog.Logger, timeout time.Duration) port.UsageRepository {
+	return &UsageRepository{db: db, logger: logger, timeout: timeout}
+}
+
+func (r *UsageRepository) Add(ctx context.Context, delta entity.Usage) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO owner_usage (owner_id, videos, source_bytes, archive_bytes, processing_seconds, updated_at)
+		VALUES ($1, GREATEST($2, 0), GREATEST($3, 0), GREATEST($4, 0), GREATEST($5, 0), NOW())
+		ON CONFLICT (owner_id) DO UPDATE SET
+			videos = GREATEST(owner_usage.videos + $2, 0),
+			source_bytes = GREATEST(owner_usage.source_bytes + $3, 0),
+			archive_bytes = GREATEST(owner_usage.archive_bytes + $4, 0),
+			processing_seconds = GREATEST(owner_usage.processing_seconds + $5, 0),
+			updated_at = NOW()`,
+		delta.OwnerId, delta.Videos, delta.SourceBytes, delta.ArchiveBytes, delta.ProcessingSeconds)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error recording usage", logging.OwnerID, delta.OwnerId, "error", err)
+	}
+
+	return err
+}
+
+func (r *UsageRepository) Reserve(ctx context.Context, delta entity.Usage, quota entity.Quota) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, "INSERT INTO owner_usage (owner_id) VALUES ($1) ON CONFLICT (owner_id) DO NOTHING", delta.OwnerId)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error reserving usage", logging.OwnerID, delta.OwnerId, "error", err)
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE owner_usage SET
+			videos = videos + $2,
+			source_bytes = source_bytes + $3,
+			archive_bytes = archive_bytes + $4,
+			processing_seconds = processing_seconds + $5,
+			updated_at = NOW()
+		WHERE owner_id = $1
+			AND ($6 = 0 OR videos + $2 <= $6)
+			AND ($7 = 0 OR source_bytes + archive_bytes + $3 + $4 <= $7)
+			AND ($8 = 0 OR processing_seconds < $8)`,
+		delta.OwnerId, delta.Videos, delta.SourceBytes, delta.ArchiveBytes, delta.ProcessingSeconds,
+		quota.MaxVideos, quota.MaxStorageBytes, quota.MaxProcessingSeconds)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error reserving usage", logging.OwnerID, delta.OwnerId, "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	// Report which limit was hit.
+	usage, err := r.Get(ctx, delta.OwnerId)
+	if err != nil {
+		return err
+	}
+	err = quota.Check(*usage, delta.Videos, delta.SourceBytes+delta.ArchiveBytes)
+	if err == nil {
+		err = entity.ErrQuotaExceeded
+	}
+
+	return err
+}
+
+func (r *UsageRepository) Get(ctx context.Context, ownerId string) (*entity.Usage, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	usage := entity.Usage{OwnerId: ownerId}
+	err := r.db.QueryRow(ctx, "SELECT videos, source_bytes, archive_bytes, processing_seconds, updated_at FROM owner_usage WHERE owner_id = $1", ownerId).
+		Scan(&usage.Videos, &usage.SourceBytes, &usage.ArchiveBytes, &usage.ProcessingSeconds, &usage.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &usage, nil
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error querying usage", logging.OwnerID, ownerId, "error", err)
+		return nil, err
+	}
+
+	return &usage, nil
+}
@@ -1,49 +1,126 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"net/url"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type UserRepository struct {
+	client *http.Client
+	logger *slog.Logger
 }
 
-func NewUserRepository() *UserRepository {
-	return &UserRepository{}
+// NewUserRepository returns a client for the user service whose requests are
+// traced, carry the W3C trace context of the caller and give up after
+// timeout.
+func NewUserRepository(logger *slog.Logger, timeout time.Duration) *UserRepository {
+	return &UserRepository{
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   timeout,
+		},
+		logger: logger,
+	}
 }
 
-func (r *UserRepository) ValidateToken(token string, ownerId string) (bool, error) {
-	req, err := http.NewRequest("POST", "http://svc-user-app/token", nil)
+func (r *UserRepository) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	if token == "" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "http://svc-user-app/token", nil)
 	if err != nil {
-		fmt.Println("Error creating request", err)
-		return false, err
+		r.logger.ErrorContext(ctx, "Error creating request", "error", err)
+		return nil, err
 	}
 
 	req.Header.Add("Authorization", token)
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := r.client.Do(req)
 	if err != nil {
-		fmt.Println("Error making request", err)
-		return false, err
+		r.logger.ErrorContext(ctx, "Error making request", "error", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var response map[string]interface{}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, entity.ErrUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError{code: resp.StatusCode}
+	}
+
+	// The user service returns numeric ids; json.Number keeps them as
+	// written instead of going through a float.
+	var response struct {
+		Id    json.Number `json:"id"`
+		Roles []string    `json:"roles"`
+	}
 
 	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error decoding response", "error", err)
+		return nil, err
+	}
+
+	if response.Id == "" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	return &entity.Principal{OwnerId: response.Id.String(), Roles: response.Roles}, nil
+}
 
+func (r *UserRepository) GetEmail(ctx context.Context, ownerId string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://svc-user-app/users/%s", url.PathEscape(ownerId)), nil)
 	if err != nil {
-		fmt.Println("Error decoding response", err)
-		return false, err
+		r.logger.ErrorContext(ctx, "Error creating request", "error", err)
+		return "", err
 	}
 
-	id, err := strconv.ParseFloat(ownerId, 64)
+	resp, err := r.client.Do(req)
 	if err != nil {
-		fmt.Println("Error parsing owner id", err)
-		return false, err
+		r.logger.ErrorContext(ctx, "Error making request", "error", err)
+		return "", err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", entity.ErrEmailNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError{code: resp.StatusCode}
+	}
+
+	var response struct {
+		Email string `json:"email"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error decoding response", "error", err)
+		return "", err
+	}
+
+	if response.Email == "" {
+		return "", entity.ErrEmailNotFound
+	}
+
+	return response.Email, nil
+}
+
+// statusError is an unexpected status the user service answered with.
+type statusError struct {
+	code int
+}
 
-	return response["id"].(float64) == id, nil
+func (e statusError) Error() string {
+	return fmt.Sprintf("user service returned status %d", e.code)
 }
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const deliveryColumns = "id, webhook_id, video_id, event, payload, status, attempts, response_code, last_error, next_attempt_at, created_at, updated_at"
+
+const claimDeliveries = `
+	UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+	WHERE id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + deliveryColumns
+
+type WebhookRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewWebhookRepository(db *pgxpool.Pool, logger *slog.Logger) port.WebhookRepository {
+	return &WebhookRepository{db: db, logger: logger}
+}
+
+func (r *WebhookRepository) Save(webhook entity.Webhook) error {
+	_, err := r.db.Exec(context.Background(), "INSERT INTO webhooks (id, owner_id, url, secret, created_at) VALUES ($1, $2, $3, $4, $5)",
+		webhook.Id, webhook.OwnerId, webhook.Url, webhook.Secret, webhook.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error saving webhook", "error", err)
+	}
+
+	return err
+}
+
+func (r *WebhookRepository) FindByOwnerId(ownerId string) ([]entity.Webhook, error) {
+	webhooks := []entity.Webhook{}
+	rows, err := r.db.Query(context.Background(), "SELECT id, owner_id, url, secret, created_at FROM webhooks WHERE owner_id = $1", ownerId)
+	if err != nil {
+		r.logger.Error("Error querying webhooks", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook := entity.Webhook{}
+		err = rows.Scan(&webhook.Id, &webhook.OwnerId, &webhook.Url, &webhook.Secret, &webhook.CreatedAt)
+		if err != nil {
+			r.logger.Error("Error scanning webhook", "error", err)
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) FindById(id string) (*entity.Webhook, error) {
+	webhook := entity.Webhook{}
+	row := r.db.QueryRow(context.Background(), "SELECT id, owner_id, url, secret, created_at FROM webhooks WHERE id = $1", id)
+	err := row.Scan(&webhook.Id, &webhook.OwnerId, &webhook.Url, &webhook.Secret, &webhook.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrWebhookNotFound
+	}
+	if err != nil {
+		r.logger.Error("Error scanning webhook", "error", err)
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) SaveDelivery(delivery entity.WebhookDelivery) error {
+	_, err := r.db.Exec(context.Background(), "INSERT INTO webhook_deliveries ("+deliveryColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT (id) DO NOTHING",
+		delivery.Id, delivery.WebhookId, delivery.VideoId, delivery.Event, delivery.Payload, delivery.Status,
+		delivery.Attempts, delivery.ResponseCode, delivery.LastError, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error saving webhook delivery", "error", err)
+	}
+
+	return err
+}
+
+func (r *WebhookRepository) UpdateDelivery(delivery entity.WebhookDelivery) error {
+	_, err := r.db.Exec(context.Background(), "UPDATE webhook_deliveries SET status = $1, attempts = $2, response_code = $3, last_error = $4, next_attempt_at = $5, updated_at = $6 WHERE id = $7",
+		delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.LastError, delivery.NextAttemptAt, delivery.UpdatedAt, delivery.Id)
+	if err != nil {
+		r.logger.Error("Error updating webhook delivery", "error", err)
+	}
+
+	return err
+}
+
+func (r *WebhookRepository) FindDeliveryById(id string) (*entity.WebhookDelivery, error) {
+	row := r.db.QueryRow(context.Background(), "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = $1", id)
+	delivery, err := scanDelivery(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrDeliveryNotFound
+	}
+	if err != nil {
+		r.logger.Error("Error scanning webhook delivery", "error", err)
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func (r *WebhookRepository) FindDeliveriesByWebhookId(webhookId string) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+	rows, err := r.db.Query(context.Background(), "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY created_at DESC", webhookId)
+	if err != nil {
+		r.logger.Error("Error querying webhook deliveries", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			r.logger.Error("Error scanning webhook delivery", "error", err)
+			return nil, err
+		}
+
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+	rows, err := r.db.Query(context.Background(), claimDeliveries, limit, lease.Milliseconds())
+	if err != nil {
+		r.logger.Error("Error claiming webhook deliveries", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			r.logger.Error("Error scanning webhook delivery", "error", err)
+			return nil, err
+		}
+
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanDelivery(row pgx.Row) (*entity.WebhookDelivery, error) {
+	delivery := entity.WebhookDelivery{}
+	err := row.Scan(&delivery.Id, &delivery.WebhookId, &delivery.VideoId, &delivery.Event, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.ResponseCode, &delivery.LastError, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
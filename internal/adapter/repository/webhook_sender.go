@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender returns a sender that refuses to connect to anything but
+// public addresses. The check runs on the address actually dialled, after
+// name resolution and on every redirect, so a host name resolving to an
+// internal address is caught too. Proxies are not used, as they would be
+// dialled instead of the receiver.
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicAddressesOnly,
+	}
+
+	return &WebhookSender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+func (s *WebhookSender) Send(url string, payload []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+func publicAddressesOnly(network string, address string, conn syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !entity.IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("webhook address %s is not allowed", addrPort.Addr())
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookSender_RefusesPrivateAddresses(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+	sender := NewWebhookSender(time.Second)
+
+	for _, url := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := sender.Send(url, []byte(`{}`), nil)
+		if err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("%s: expected the address to be refused, got %v", url, err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("expected no request to reach the server, got %d", calls)
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+func GetEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func GetEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer, using default", "key", key, "default", fallback, "error", err)
+		return fallback
+	}
+	return parsed
+}
+
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid duration, using default", "key", key, "default", fallback, "error", err)
+		return fallback
+	}
+	return parsed
+}
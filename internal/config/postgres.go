@@ -16,23 +16,245 @@ const (
 			owner_id VARCHAR(255) NOT NULL,
 			status VARCHAR(20) NOT NULL
 		);
+
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS name VARCHAR(255) NOT NULL DEFAULT '';
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS source_key VARCHAR(255) NOT NULL DEFAULT '';
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS last_error TEXT NOT NULL DEFAULT '';
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS interval_seconds INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS worker_id VARCHAR(255) NOT NULL DEFAULT '';
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS source_bytes BIGINT NOT NULL DEFAULT 0;
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+		ALTER TABLE videos ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+
+		-- Jobs saved before uploads were claimed, or released before release
+		-- marked them stale, have no heartbeat; they are stale.
+		UPDATE videos SET heartbeat_at = '-infinity' WHERE status = 'processing' AND heartbeat_at IS NULL;
+
+		CREATE INDEX IF NOT EXISTS videos_processing_heartbeat_idx ON videos (heartbeat_at) WHERE status = 'processing';
+		CREATE INDEX IF NOT EXISTS videos_owner_id_created_at_idx ON videos (owner_id, created_at);
+
+		CREATE TABLE IF NOT EXISTS video_archives (
+			video_id VARCHAR(255) NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			key VARCHAR(255) NOT NULL,
+			frame_count INTEGER NOT NULL DEFAULT 0,
+			interval_seconds INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (video_id, version)
+		);
+
+		ALTER TABLE video_archives ADD COLUMN IF NOT EXISTS size_bytes BIGINT NOT NULL DEFAULT 0;
+
+		-- Videos finished before finished_at was tracked: their latest archive
+		-- is the best record of when they finished.
+		UPDATE videos SET finished_at = COALESCE((SELECT MAX(a.created_at) FROM video_archives a WHERE a.video_id = videos.id), created_at)
+		WHERE finished_at IS NULL AND status <> 'processing';
+
+		CREATE TABLE IF NOT EXISTS outbox (
+			id VARCHAR(255) PRIMARY KEY,
+			video_id VARCHAR(255) NOT NULL,
+			event VARCHAR(50) NOT NULL,
+			payload JSONB NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			locked_until TIMESTAMPTZ,
+			published_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (created_at) WHERE published_at IS NULL;
+
+		ALTER TABLE outbox ADD COLUMN IF NOT EXISTS delivered_sinks TEXT[] NOT NULL DEFAULT '{}';
+		ALTER TABLE outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+		CREATE INDEX IF NOT EXISTS outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id VARCHAR(255) PRIMARY KEY,
+			owner_id VARCHAR(255) NOT NULL,
+			url TEXT NOT NULL,
+			secret VARCHAR(255) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS webhooks_owner_id_idx ON webhooks (owner_id);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id VARCHAR(255) PRIMARY KEY,
+			webhook_id VARCHAR(255) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+			video_id VARCHAR(255) NOT NULL,
+			event VARCHAR(50) NOT NULL,
+			payload BYTEA NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response_code INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id);
+
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+		-- Deliveries left pending by the in-memory retries that preceded the
+		-- worker are picked up by it.
+		UPDATE webhook_deliveries SET next_attempt_at = updated_at WHERE status = 'pending' AND next_attempt_at IS NULL;
+
+		CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id VARCHAR(255) PRIMARY KEY,
+			owner_id VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			hash CHAR(64) NOT NULL UNIQUE,
+			scopes TEXT[] NOT NULL,
+			expires_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS api_keys_owner_id_idx ON api_keys (owner_id);
+
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id VARCHAR(255) PRIMARY KEY,
+			actor_id VARCHAR(255) NOT NULL,
+			action VARCHAR(50) NOT NULL,
+			target_id VARCHAR(255) NOT NULL DEFAULT '',
+			details JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+
+		CREATE TABLE IF NOT EXISTS share_grants (
+			id VARCHAR(255) PRIMARY KEY,
+			video_id VARCHAR(255) NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+			owner_id VARCHAR(255) NOT NULL,
+			grantee_id VARCHAR(255) NOT NULL,
+			permission VARCHAR(50) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			revoked_at TIMESTAMPTZ
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS share_grants_active_idx ON share_grants (video_id, grantee_id) WHERE revoked_at IS NULL;
+
+		CREATE TABLE IF NOT EXISTS share_links (
+			id VARCHAR(255) PRIMARY KEY,
+			video_id VARCHAR(255) NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+			owner_id VARCHAR(255) NOT NULL,
+			version INT NOT NULL DEFAULT 0,
+			expires_at TIMESTAMPTZ NOT NULL,
+			max_downloads INT NOT NULL DEFAULT 0,
+			downloads INT NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			revoked_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS share_links_video_id_idx ON share_links (video_id);
+
+		CREATE TABLE IF NOT EXISTS owner_usage (
+			owner_id VARCHAR(255) PRIMARY KEY,
+			videos INTEGER NOT NULL DEFAULT 0,
+			source_bytes BIGINT NOT NULL DEFAULT 0,
+			archive_bytes BIGINT NOT NULL DEFAULT 0,
+			processing_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS applied_migrations (
+			name VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		-- Usage of videos uploaded before it was tracked, counted once. Rows
+		-- recorded since then are overwritten with the full count; processing
+		-- time can't be recovered and is kept.
+		WITH first_run AS (
+			INSERT INTO applied_migrations (name) VALUES ('owner_usage_backfill')
+			ON CONFLICT (name) DO NOTHING
+			RETURNING name
+		)
+		INSERT INTO owner_usage (owner_id, videos, source_bytes, archive_bytes, updated_at)
+		SELECT v.owner_id,
+			COUNT(*),
+			COALESCE(SUM(v.source_bytes) FILTER (WHERE v.status <> 'expired'), 0),
+			COALESCE(SUM(a.size_bytes) FILTER (WHERE v.status <> 'expired'), 0),
+			NOW()
+		FROM videos v
+		LEFT JOIN (SELECT video_id, SUM(size_bytes) AS size_bytes FROM video_archives GROUP BY video_id) a ON a.video_id = v.id
+		WHERE EXISTS (SELECT 1 FROM first_run)
+		GROUP BY v.owner_id
+		ON CONFLICT (owner_id) DO UPDATE SET
+			videos = EXCLUDED.videos,
+			source_bytes = EXCLUDED.source_bytes,
+			archive_bytes = EXCLUDED.archive_bytes,
+			updated_at = NOW();
+
+		CREATE TABLE IF NOT EXISTS rate_limits (
+			key VARCHAR(255) PRIMARY KEY,
+			tat TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS owner_erasures (
+			id VARCHAR(255) PRIMARY KEY,
+			owner_id VARCHAR(255) NOT NULL,
+			requested_by VARCHAR(255) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			videos_erased INTEGER NOT NULL DEFAULT 0,
+			records_erased BIGINT NOT NULL DEFAULT 0,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			completed_at TIMESTAMPTZ,
+			locked_until TIMESTAMPTZ
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS owner_erasures_active_idx ON owner_erasures (owner_id) WHERE status <> 'completed';
+
+		-- Every status change, kept for exports after the outbox has pruned
+		-- its copy and after the video itself is deleted.
+		CREATE TABLE IF NOT EXISTS video_status_history (
+			id VARCHAR(255) PRIMARY KEY,
+			video_id VARCHAR(255) NOT NULL,
+			owner_id VARCHAR(255) NOT NULL,
+			status VARCHAR(50) NOT NULL,
+			payload JSONB NOT NULL,
+			occurred_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS video_status_history_owner_idx ON video_status_history (owner_id, occurred_at);
+
+		-- History from before it was kept, as far as the outbox still has it.
+		WITH first_run AS (
+			INSERT INTO applied_migrations (name) VALUES ('video_status_history_backfill')
+			ON CONFLICT (name) DO NOTHING
+			RETURNING name
+		)
+		INSERT INTO video_status_history (id, video_id, owner_id, status, payload, occurred_at)
+		SELECT id, video_id, payload->>'owner_id', payload->>'status', payload, created_at
+		FROM outbox
+		WHERE EXISTS (SELECT 1 FROM first_run)
+		ON CONFLICT (id) DO NOTHING;
     `
 )
 
 func NewPostgresDb(url string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(url)
 	if err != nil {
-		fmt.Println("Error parsing config", err)
+		return nil, fmt.Errorf("parsing database config: %w", err)
 	}
+	config.ConnConfig.Tracer = queryTracer{}
 	db, err = pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
-		fmt.Println("Error creating database connection", err)
-		return nil, err
+		return nil, fmt.Errorf("creating database connection: %w", err)
 	}
 
 	if _, err := db.Exec(context.Background(), createTables); err != nil {
-		fmt.Println("Error creating table Persons", err)
-		return nil, err
+		return nil, fmt.Errorf("creating tables: %w", err)
 	}
 
 	return db, err
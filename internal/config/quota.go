@@ -0,0 +1,12 @@
+package config
+
+import "github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+// LoadQuota reads the per-owner quota; a limit of zero disables it.
+func LoadQuota() entity.Quota {
+	return entity.Quota{
+		MaxVideos:            GetEnvInt("QUOTA_MAX_VIDEOS", 0),
+		MaxStorageBytes:      int64(GetEnvInt("QUOTA_MAX_STORAGE_MB", 0)) << 20,
+		MaxProcessingSeconds: GetEnvDuration("QUOTA_MAX_PROCESSING_TIME", 0).Seconds(),
+	}
+}
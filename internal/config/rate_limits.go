@@ -0,0 +1,90 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// RateLimits holds the per-owner limit of each route, keyed by its pattern.
+// Routes without an entry of their own get Default.
+type RateLimits struct {
+	Default   entity.RateLimit
+	Endpoints map[string]entity.RateLimit
+}
+
+// LoadRateLimits reads RATE_LIMIT_DEFAULT and RATE_LIMITS, a comma separated
+// list of pattern=limit overrides. Limits are written as requests/period,
+// e.g. "10/1m"; "0" disables the limit.
+func LoadRateLimits() RateLimits {
+	limits := RateLimits{
+		Default: GetEnvRateLimit("RATE_LIMIT_DEFAULT", entity.RateLimit{Requests: 300, Period: time.Minute}),
+		Endpoints: map[string]entity.RateLimit{
+			"/video":                 {Requests: 10, Period: time.Minute},
+			"/videos/{id}/reprocess": {Requests: 10, Period: time.Minute},
+			"/owners/{id}/export":    {Requests: 5, Period: time.Hour},
+		},
+	}
+
+	for _, override := range strings.Split(os.Getenv("RATE_LIMITS"), ",") {
+		if strings.TrimSpace(override) == "" {
+			continue
+		}
+		pattern, value, _ := strings.Cut(override, "=")
+		limit, ok := parseRateLimit(value)
+		if !ok {
+			slog.Warn("Invalid rate limit, ignoring", "key", "RATE_LIMITS", "value", override)
+			continue
+		}
+		limits.Endpoints[strings.TrimSpace(pattern)] = limit
+	}
+
+	return limits
+}
+
+func (l RateLimits) For(pattern string) entity.RateLimit {
+	if limit, ok := l.Endpoints[pattern]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+func GetEnvRateLimit(key string, fallback entity.RateLimit) entity.RateLimit {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	limit, ok := parseRateLimit(value)
+	if !ok {
+		slog.Warn("Invalid rate limit, using default", "key", key, "value", value)
+		return fallback
+	}
+	return limit
+}
+
+func parseRateLimit(value string) (entity.RateLimit, bool) {
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		return entity.RateLimit{}, true
+	}
+
+	rawRequests, rawPeriod, ok := strings.Cut(value, "/")
+	if !ok {
+		return entity.RateLimit{}, false
+	}
+	requests, err := strconv.Atoi(rawRequests)
+	if err != nil || requests < 0 {
+		return entity.RateLimit{}, false
+	}
+	period, err := time.ParseDuration(rawPeriod)
+	if err != nil || period <= 0 {
+		return entity.RateLimit{}, false
+	}
+
+	return entity.RateLimit{Requests: requests, Period: period}, true
+}
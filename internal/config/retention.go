@@ -0,0 +1,35 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// LoadRetention reads RETENTION_PERIOD and RETENTION_OWNER_PERIODS, a comma
+// separated list of owner_id=period overrides, e.g. "42=2160h". A period of
+// zero keeps archives forever.
+func LoadRetention() entity.RetentionPolicy {
+	policy := entity.RetentionPolicy{
+		Default: GetEnvDuration("RETENTION_PERIOD", 0),
+		Owners:  map[string]time.Duration{},
+	}
+
+	for _, override := range strings.Split(os.Getenv("RETENTION_OWNER_PERIODS"), ",") {
+		if strings.TrimSpace(override) == "" {
+			continue
+		}
+		ownerId, value, _ := strings.Cut(override, "=")
+		period, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || period < 0 {
+			slog.Warn("Invalid retention period, ignoring", "key", "RETENTION_OWNER_PERIODS", "value", override)
+			continue
+		}
+		policy.Owners[strings.TrimSpace(ownerId)] = period
+	}
+
+	return policy
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// Timeouts bounds each kind of outbound operation so a stuck dependency
+// cannot hold a request or a job forever. Zero disables the timeout.
+type Timeouts struct {
+	Database        time.Duration
+	UserService     time.Duration
+	StorageUpload   time.Duration
+	StorageDownload time.Duration
+	FFmpeg          time.Duration
+	Zip             time.Duration
+}
+
+func LoadTimeouts() Timeouts {
+	return Timeouts{
+		Database:        GetEnvDuration("DB_TIMEOUT", 5*time.Second),
+		UserService:     GetEnvDuration("USER_SERVICE_TIMEOUT", 3*time.Second),
+		StorageUpload:   GetEnvDuration("STORAGE_UPLOAD_TIMEOUT", 5*time.Minute),
+		StorageDownload: GetEnvDuration("STORAGE_DOWNLOAD_TIMEOUT", 5*time.Minute),
+		FFmpeg:          GetEnvDuration("FFMPEG_TIMEOUT", 30*time.Minute),
+		Zip:             GetEnvDuration("ZIP_TIMEOUT", 10*time.Minute),
+	}
+}
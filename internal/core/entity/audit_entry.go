@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditListVideos  = "videos.list"
+	AuditForceStatus = "video.force_status"
+	AuditReprocess   = "video.reprocess"
+	AuditPurge       = "video.purge"
+	AuditExportOwner = "owner.export"
+	AuditEraseOwner  = "owner.erase"
+	// AuditActAsOwner records an admin using an owner's endpoints on the
+	// owner's behalf.
+	AuditActAsOwner = "owner.act_as"
+)
+
+// AuditEntry records an action taken by an admin, written before the action
+// runs so attempts are recorded even when they fail.
+type AuditEntry struct {
+	Id        string            `json:"id"`
+	ActorId   string            `json:"actor_id"`
+	Action    string            `json:"action"`
+	TargetId  string            `json:"target_id,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func NewAuditEntry(actorId string, action string, targetId string, details map[string]string) AuditEntry {
+	return AuditEntry{
+		Id:        uuid.New().String(),
+		ActorId:   actorId,
+		Action:    action,
+		TargetId:  targetId,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	}
+}
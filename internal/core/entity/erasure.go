@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErasurePending   = "pending"
+	ErasureRunning   = "running"
+	ErasureCompleted = "completed"
+)
+
+// OwnerErasure tracks the asynchronous erasure of everything stored for an
+// owner. It holds none of the owner's data, so once completed it is kept as
+// the receipt that the erasure happened.
+type OwnerErasure struct {
+	Id          string
+	OwnerId     string
+	RequestedBy string
+	Status      string
+	// VideosErased and RecordsErased count the videos, and the other rows
+	// such as webhooks and API keys, removed so far.
+	VideosErased  int
+	RecordsErased int64
+	Attempts      int
+	LastError     string
+	RequestedAt   time.Time
+	CompletedAt   *time.Time
+}
+
+type OwnerErasureResponse struct {
+	Id            string     `json:"id"`
+	OwnerId       string     `json:"owner_id"`
+	RequestedBy   string     `json:"requested_by"`
+	Status        string     `json:"status"`
+	VideosErased  int        `json:"videos_erased"`
+	RecordsErased int64      `json:"records_erased"`
+	LastError     string     `json:"last_error,omitempty"`
+	RequestedAt   time.Time  `json:"requested_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+func NewOwnerErasure(ownerId string, requestedBy string) OwnerErasure {
+	return OwnerErasure{
+		Id:          uuid.New().String(),
+		OwnerId:     ownerId,
+		RequestedBy: requestedBy,
+		Status:      ErasurePending,
+		RequestedAt: time.Now().UTC(),
+	}
+}
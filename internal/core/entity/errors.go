@@ -0,0 +1,55 @@
+package entity
+
+import "errors"
+
+var (
+	ErrVideoNotFound = errors.New("Video not found")
+	// ErrStatusConflict is returned when a video is not in the status a
+	// transition expects, e.g. cancelling a video that already finished.
+	ErrStatusConflict = errors.New("Video status conflict")
+	// ErrSourceUnavailable is returned when a video can't be reprocessed
+	// because its original upload was not retained.
+	ErrSourceUnavailable = errors.New("Video source is no longer available")
+	// ErrVideoExpired is returned for videos whose archives were deleted by
+	// the retention policy.
+	ErrVideoExpired = errors.New("Video has expired")
+	// ErrVideoNotReady is returned for downloads of archives that haven't
+	// been produced, e.g. while the video is still processing.
+	ErrVideoNotReady     = errors.New("Video not ready to download")
+	ErrInvalidParameters = errors.New("Invalid processing parameters")
+	// ErrInvalidVideo is returned for uploads that aren't mp4 videos; it is
+	// wrapped with what was wrong.
+	ErrInvalidVideo   = errors.New("Invalid video file")
+	ErrShuttingDown   = errors.New("Service is shutting down")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrTooManyJobs is returned when the owner already has as many videos
+	// processing as they are allowed to.
+	ErrTooManyJobs = errors.New("Too many videos processing")
+	// ErrQuotaExceeded is returned when accepting new work would take the
+	// owner over their quota; it is wrapped with the limit that was hit.
+	ErrQuotaExceeded = errors.New("Quota exceeded")
+	ErrShareNotFound = errors.New("Share not found")
+	// ErrInvalidWebhookURL is returned for webhook urls that aren't http(s)
+	// or point at a private address.
+	ErrInvalidWebhookURL = errors.New("Invalid webhook url")
+	ErrWebhookNotFound   = errors.New("Webhook not found")
+	ErrDeliveryNotFound  = errors.New("Delivery not found")
+	ErrErasureNotFound   = errors.New("Erasure not found")
+	// ErrErasureInProgress is returned for new work from owners whose data
+	// is being erased.
+	ErrErasureInProgress = errors.New("Owner data is being erased")
+	// ErrShareLinkUnavailable is returned for links that are expired,
+	// revoked or out of downloads.
+	ErrShareLinkUnavailable = errors.New("Share link is no longer available")
+	// ErrShareLinksDisabled is returned when no signing secret is configured.
+	ErrShareLinksDisabled = errors.New("Public links are not enabled")
+	// ErrUnauthorized is returned for missing, malformed or expired tokens,
+	// as opposed to failures to check them.
+	ErrUnauthorized = errors.New("Invalid token")
+	// ErrEmailNotFound is returned when the user service holds no email
+	// address for an owner, including owners it doesn't know.
+	ErrEmailNotFound = errors.New("Email address not found")
+	// ErrAuthUnavailable is returned when tokens can't be checked because
+	// the identity provider is down.
+	ErrAuthUnavailable = errors.New("Authentication is temporarily unavailable")
+)
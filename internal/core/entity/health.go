@@ -0,0 +1,21 @@
+package entity
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+type HealthCheckResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+func (h HealthReport) Healthy() bool {
+	return h.Status == HealthStatusUp
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type OutboxMessage struct {
+	Id        string
+	VideoId   string
+	Event     string
+	Payload   []byte
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	// Delivered lists the sinks that already accepted the message.
+	Delivered []string
+}
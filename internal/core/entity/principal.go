@@ -0,0 +1,39 @@
+package entity
+
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller, resolved once per request from its
+// token.
+type Principal struct {
+	OwnerId string
+	Roles   []string
+	// APIKeyId and Scopes are set when the caller used an API key, which
+	// is limited to its scopes; user tokens can do anything.
+	APIKeyId string
+	Scopes   []string
+}
+
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin)
+}
+
+func (p Principal) HasScope(scope string) bool {
+	if p.APIKeyId == "" {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
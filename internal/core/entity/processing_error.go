@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"context"
+	"errors"
+)
+
+// PermanentError marks a processing failure that retrying can't fix, such as
+// a corrupt input file.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsTransient reports whether a failed job is worth retrying. Errors are
+// considered transient unless they were marked permanent or the job was
+// cancelled.
+func IsTransient(err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, ErrStatusConflict)
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// RateLimit allows Requests per Period, in bursts of up to Requests.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
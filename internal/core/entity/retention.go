@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// RetentionPolicy is how long finished videos keep their archives. Owners
+// can have a period of their own; zero keeps archives forever.
+type RetentionPolicy struct {
+	Default time.Duration
+	Owners  map[string]time.Duration
+}
+
+func (p RetentionPolicy) For(ownerId string) time.Duration {
+	if period, ok := p.Owners[ownerId]; ok {
+		return period
+	}
+	return p.Default
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareGrant gives another owner access to a video's archives. Permission
+// is ScopeRead, to list them, or ScopeDownload, which also lists them.
+type ShareGrant struct {
+	Id         string
+	VideoId    string
+	OwnerId    string
+	GranteeId  string
+	Permission string
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+type ShareGrantResponse struct {
+	Id         string     `json:"id"`
+	VideoId    string     `json:"video_id"`
+	GranteeId  string     `json:"grantee_id"`
+	Permission string     `json:"permission"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ShareLink is a public download link for one archive version, or the
+// latest one when Version is zero. MaxDownloads of zero means unlimited.
+type ShareLink struct {
+	Id           string
+	VideoId      string
+	OwnerId      string
+	Version      int
+	ExpiresAt    time.Time
+	MaxDownloads int
+	Downloads    int
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+}
+
+type ShareLinkResponse struct {
+	Id           string     `json:"id"`
+	VideoId      string     `json:"video_id"`
+	Url          string     `json:"url,omitempty"`
+	Version      int        `json:"version,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	MaxDownloads int        `json:"max_downloads,omitempty"`
+	Downloads    int        `json:"downloads"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+func NewShareGrant(video VideoFile, granteeId string, permission string) (*ShareGrant, error) {
+	if permission != ScopeRead && permission != ScopeDownload {
+		return nil, fmt.Errorf("%w: permission must be %s or %s", ErrInvalidParameters, ScopeRead, ScopeDownload)
+	}
+	if granteeId == "" || granteeId == video.OwnerId {
+		return nil, fmt.Errorf("%w: invalid grantee", ErrInvalidParameters)
+	}
+
+	return &ShareGrant{
+		Id:         uuid.New().String(),
+		VideoId:    video.Id,
+		OwnerId:    video.OwnerId,
+		GranteeId:  granteeId,
+		Permission: permission,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// Allows reports whether the grant covers permission; download access
+// includes read access.
+func (g ShareGrant) Allows(permission string) bool {
+	return g.RevokedAt == nil && (g.Permission == permission || g.Permission == ScopeDownload)
+}
+
+func NewShareLink(video VideoFile, version int, ttl time.Duration, maxDownloads int) (*ShareLink, error) {
+	if ttl <= 0 || version < 0 || maxDownloads < 0 {
+		return nil, ErrInvalidParameters
+	}
+
+	now := time.Now().UTC()
+	return &ShareLink{
+		Id:           uuid.New().String(),
+		VideoId:      video.Id,
+		OwnerId:      video.OwnerId,
+		Version:      version,
+		ExpiresAt:    now.Add(ttl),
+		MaxDownloads: maxDownloads,
+		CreatedAt:    now,
+	}, nil
+}
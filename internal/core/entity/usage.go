@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Usage is what an owner currently consumes. It is also used as a delta
+// when the pipeline records changes, so its fields may be negative there.
+type Usage struct {
+	OwnerId           string
+	Videos            int
+	SourceBytes       int64
+	ArchiveBytes      int64
+	ProcessingSeconds float64
+	UpdatedAt         time.Time
+}
+
+func (u Usage) StorageBytes() int64 {
+	return u.SourceBytes + u.ArchiveBytes
+}
+
+// Quota limits what each owner can consume; zero means unlimited.
+type Quota struct {
+	MaxVideos            int
+	MaxStorageBytes      int64
+	MaxProcessingSeconds float64
+}
+
+// Check reports whether adding videos and bytes on top of usage stays
+// within the quota. Processing time can't be known up front, so new work is
+// refused once the budget is used up rather than when it would be.
+func (q Quota) Check(usage Usage, videos int, bytes int64) error {
+	if q.MaxVideos > 0 && usage.Videos+videos > q.MaxVideos {
+		return fmt.Errorf("%w: videos", ErrQuotaExceeded)
+	}
+	if q.MaxStorageBytes > 0 && usage.StorageBytes()+bytes > q.MaxStorageBytes {
+		return fmt.Errorf("%w: storage", ErrQuotaExceeded)
+	}
+	if q.MaxProcessingSeconds > 0 && usage.ProcessingSeconds >= q.MaxProcessingSeconds {
+		return fmt.Errorf("%w: processing time", ErrQuotaExceeded)
+	}
+
+	return nil
+}
+
+type UsageResponse struct {
+	Videos            int           `json:"videos"`
+	SourceBytes       int64         `json:"source_bytes"`
+	ArchiveBytes      int64         `json:"archive_bytes"`
+	StorageBytes      int64         `json:"storage_bytes"`
+	ProcessingSeconds float64       `json:"processing_seconds"`
+	Limits            QuotaResponse `json:"limits"`
+	UpdatedAt         *time.Time    `json:"updated_at,omitempty"`
+}
+
+// QuotaResponse omits the limits that are not enforced.
+type QuotaResponse struct {
+	MaxVideos            int     `json:"max_videos,omitempty"`
+	MaxStorageBytes      int64   `json:"max_storage_bytes,omitempty"`
+	MaxProcessingSeconds float64 `json:"max_processing_seconds,omitempty"`
+}
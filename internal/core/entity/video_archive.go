@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// VideoArchive is one generated frames archive. Reprocessing a video adds a
+// new version while earlier ones stay downloadable.
+type VideoArchive struct {
+	VideoId         string
+	Version         int
+	Key             string
+	FrameCount      int
+	IntervalSeconds int
+	// Size is the archive's size in bytes.
+	Size      int64
+	CreatedAt time.Time
+}
+
+type VideoArchiveResponse struct {
+	Version         int       `json:"version"`
+	FrameCount      int       `json:"frame_count"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func NewVideoArchive(video VideoFile) VideoArchive {
+	return VideoArchive{
+		VideoId:         video.Id,
+		Version:         video.Version,
+		Key:             video.GetZipFileName(),
+		FrameCount:      video.FrameCount,
+		IntervalSeconds: video.IntervalSeconds,
+		CreatedAt:       time.Now().UTC(),
+	}
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventVideoReady        = "video.ready_to_download"
+	EventVideoFailed       = "video.error"
+	EventVideoCancelled    = "video.cancelled"
+	EventVideoReprocessing = "video.reprocessing"
+	EventVideoDeadLettered = "video.dead_letter"
+	EventVideoExpired      = "video.expired"
+	// EventVideoStatusForced is emitted when an admin overrides a status.
+	EventVideoStatusForced = "video.status_forced"
+)
+
+// VideoEvent is emitted whenever a video reaches a terminal status.
+type VideoEvent struct {
+	Id         string    `json:"id"`
+	Event      string    `json:"event"`
+	VideoId    string    `json:"video_id"`
+	OwnerId    string    `json:"owner_id"`
+	Status     string    `json:"status"`
+	VideoName  string    `json:"video_name,omitempty"`
+	FrameCount int       `json:"frame_count,omitempty"`
+	Version    int       `json:"version,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func NewVideoEvent(video VideoFile, event string) VideoEvent {
+	return VideoEvent{
+		Id:         uuid.New().String(),
+		Event:      event,
+		VideoId:    video.Id,
+		OwnerId:    video.OwnerId,
+		Status:     video.Status,
+		VideoName:  video.GetOriginalName(),
+		FrameCount: video.FrameCount,
+		Version:    video.Version,
+		OccurredAt: time.Now().UTC(),
+	}
+}
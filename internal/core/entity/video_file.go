@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,28 +18,42 @@ const (
 )
 
 type VideoFile struct {
-	OwnerId string
-	File    multipart.File
-	Header  *multipart.FileHeader
-	Id      string
-	Status  string
+	OwnerId    string
+	File       multipart.File
+	Header     *multipart.FileHeader
+	Id         string
+	Name       string
+	Status     string
+	FrameCount int
+	SourceKey  string
+	// SourceSize is the size in bytes of the retained upload.
+	SourceSize      int64
+	Version         int
+	IntervalSeconds int
+	Attempts        int
+	LastError       string
+	CreatedAt       time.Time
+	// FinishedAt is when the latest run finished, nil while processing.
+	FinishedAt *time.Time
 }
 
 type VideoFileResponse struct {
-	OwnerId string `json:"owner_id"`
-	Id      string `json:"id"`
-	Status  string `json:"status"`
+	OwnerId    string     `json:"owner_id"`
+	Id         string     `json:"id"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
 }
 
 func NewVideoFile(file multipart.File, header *multipart.FileHeader, ownerId string) (*VideoFile, error) {
 	if !isExtensionValid(header) {
-		fmt.Println("Invalid file format. Only .mp4 files are allowed", http.StatusUnsupportedMediaType)
-		return nil, fmt.Errorf("Invalid file format: %s", header.Filename)
+		return nil, fmt.Errorf("%w: unsupported file format: %s", ErrInvalidVideo, header.Filename)
 	}
 
 	if valid, mimeType := isMimeTypeValid(file); !valid {
-		fmt.Println("Detected MIME type:", mimeType)
-		return nil, fmt.Errorf("Invalid MIME type: %s", mimeType)
+		return nil, fmt.Errorf("%w: unsupported MIME type: %s", ErrInvalidVideo, mimeType)
 	}
 
 	id := uuid.New().String()
@@ -49,11 +64,14 @@ func NewVideoFile(file multipart.File, header *multipart.FileHeader, ownerId str
 	}
 
 	return &VideoFile{
-		OwnerId: ownerId,
-		File:    file,
-		Header:  header,
-		Id:      id,
-		Status:  "processing",
+		OwnerId:   ownerId,
+		File:      file,
+		Header:    header,
+		Id:        id,
+		Name:      header.Filename,
+		Status:    "processing",
+		Version:   1,
+		CreatedAt: time.Now().UTC(),
 	}, nil
 }
 
@@ -76,17 +94,14 @@ func save(file multipart.File, header *multipart.FileHeader, id string) error {
 	filename := fmt.Sprintf("./%s.mp4", id)
 	dst, err := os.Create(filename)
 	if err != nil {
-		fmt.Println("File creation error:", err)
-		return fmt.Errorf("Unable to create the file: %s", filename)
+		return fmt.Errorf("Unable to create the file %s: %w", filename, err)
 	}
 	defer dst.Close()
 
 	_, err = io.Copy(dst, file)
 	if err != nil {
-		fmt.Println("Copy error:", err)
-		return fmt.Errorf("Unable to save the file: %s", filename)
+		return fmt.Errorf("Unable to save the file %s: %w", filename, err)
 	}
-	fmt.Println("File uploaded:", header.Filename, filename)
 
 	return nil
 }
@@ -95,17 +110,60 @@ func (v *VideoFile) GetFileName() string {
 	return fmt.Sprintf("%s.mp4", v.Id)
 }
 
+// GetZipFileName returns the storage key of the archive version being
+// produced; the first version keeps the original <id>.zip key.
 func (v *VideoFile) GetZipFileName() string {
-	return fmt.Sprintf("%s.zip", v.Id)
+	return GetArchiveKey(v.Id, v.Version)
 }
 
-func (v *VideoFile) Delete() error {
-	err := os.Remove(v.GetFileName())
-	if err != nil {
-		fmt.Println("Error deleting file with id:", v.Id, err)
-		return err
+func (v *VideoFile) GetOutputZipPath() string {
+	return fmt.Sprintf("output_%s", v.GetZipFileName())
+}
+
+func (v *VideoFile) GetSourceKey() string {
+	return fmt.Sprintf("sources/%s.mp4", v.Id)
+}
+
+// SortKey returns the time the video is listed by under sort.
+func (v *VideoFile) SortKey(sort string) time.Time {
+	if sort != SortFinishedAt {
+		return v.CreatedAt
 	}
-	return nil
+	if v.FinishedAt == nil {
+		return UnfinishedSortKey
+	}
+
+	return *v.FinishedAt
+}
+
+func GetArchiveKey(videoId string, version int) string {
+	if version <= 1 {
+		return fmt.Sprintf("%s.zip", videoId)
+	}
+	return fmt.Sprintf("%s_v%d.zip", videoId, version)
+}
+
+// GetFramesOutput is the ffmpeg output template for this video's frames.
+func (v *VideoFile) GetFramesOutput() string {
+	return fmt.Sprintf("frame_%s_%%04d.png", v.Id)
+}
+
+func (v *VideoFile) GetFramesPattern() string {
+	return fmt.Sprintf("frame_%s_*.png", v.Id)
+}
+
+func (v *VideoFile) GetOriginalName() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	if v.Header != nil {
+		return v.Header.Filename
+	}
+	return v.GetFileName()
+}
+
+func (v *VideoFile) Delete() error {
+	return os.Remove(v.GetFileName())
 }
 
 func (v *VideoFile) ErrorProcessing() {
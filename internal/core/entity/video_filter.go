@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SortCreatedAt  = "created_at"
+	SortFinishedAt = "finished_at"
+)
+
+// UnfinishedSortKey stands in for the finish time of videos that haven't
+// finished yet, so they sort after every finished one.
+var UnfinishedSortKey = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// VideoFilter narrows a listing of videos across owners; empty fields match
+// everything.
+type VideoFilter struct {
+	OwnerId string
+	Status  string
+	// CreatedAfter and CreatedBefore bound the upload time, inclusively and
+	// exclusively.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Sort is SortCreatedAt, the default, or SortFinishedAt. Ties are broken
+	// by id.
+	Sort       string
+	Descending bool
+	// After continues a listing from the cursor returned with its previous
+	// page.
+	After *VideoCursor
+	Limit int
+}
+
+// VideoCursor points at the last video of a page, by its sort key and id,
+// under the sort order the page was listed in.
+type VideoCursor struct {
+	Sort       string    `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Key        time.Time `json:"k"`
+	Id         string    `json:"i"`
+}
+
+// VideoPage is one page of an owner's videos. Total counts every match,
+// not just this page, and NextCursor is empty on the last page.
+type VideoPage struct {
+	Videos     []VideoFileResponse
+	Total      int
+	NextCursor string
+}
+
+// ParseVideoSort reads a sort parameter such as "created_at", or
+// "-finished_at" for descending order. An empty one lists the newest
+// uploads first.
+func ParseVideoSort(value string) (string, bool, error) {
+	if value == "" {
+		return SortCreatedAt, true, nil
+	}
+
+	sort, descending := strings.CutPrefix(value, "-")
+	if sort != SortCreatedAt && sort != SortFinishedAt {
+		return "", false, fmt.Errorf("%w: unknown sort %q", ErrInvalidParameters, value)
+	}
+
+	return sort, descending, nil
+}
+
+func NewVideoCursor(video VideoFile, filter VideoFilter) VideoCursor {
+	return VideoCursor{
+		Sort:       filter.Sort,
+		Descending: filter.Descending,
+		Key:        video.SortKey(filter.Sort),
+		Id:         video.Id,
+	}
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c VideoCursor) Encode() string {
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// ParseVideoCursor decodes a cursor returned by Encode, which must have
+// been issued for filter's sort order.
+func ParseVideoCursor(value string, filter VideoFilter) (*VideoCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidParameters)
+	}
+
+	cursor := VideoCursor{}
+	err = json.Unmarshal(decoded, &cursor)
+	if err != nil || cursor.Id == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidParameters)
+	}
+	if cursor.Sort != filter.Sort || cursor.Descending != filter.Descending {
+		return nil, fmt.Errorf("%w: cursor is for another sort order", ErrInvalidParameters)
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	Id        string
+	OwnerId   string
+	Url       string
+	Secret    string
+	CreatedAt time.Time
+}
+
+type WebhookResponse struct {
+	Id        string    `json:"id"`
+	OwnerId   string    `json:"owner_id"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	Id           string
+	WebhookId    string
+	VideoId      string
+	Event        string
+	Payload      []byte
+	Status       string
+	Attempts     int
+	ResponseCode int
+	LastError    string
+	// NextAttemptAt is when a pending delivery is next due; nil once it
+	// was delivered or gave up.
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type WebhookDeliveryResponse struct {
+	Id           string    `json:"id"`
+	WebhookId    string    `json:"webhook_id"`
+	VideoId      string    `json:"video_id"`
+	Event        string    `json:"event"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	ResponseCode int       `json:"response_code"`
+	LastError    string    `json:"last_error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewWebhook rejects urls that aren't http(s) or name a private address
+// outright. Host names are checked again when delivering, since they may
+// resolve anywhere.
+func NewWebhook(ownerId string, rawUrl string) (*Webhook, error) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Hostname() == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWebhookURL, rawUrl)
+	}
+	if addr, err := netip.ParseAddr(parsed.Hostname()); (err == nil && !IsPublicAddress(addr)) || parsed.Hostname() == "localhost" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWebhookURL, rawUrl)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		Id:        uuid.New().String(),
+		OwnerId:   ownerId,
+		Url:       parsed.String(),
+		Secret:    hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// NewWebhookDelivery derives the delivery's id from the event and webhook,
+// so the same event is only ever recorded once per webhook.
+func NewWebhookDelivery(webhookId string, event VideoEvent, payload []byte) *WebhookDelivery {
+	now := time.Now().UTC()
+	return &WebhookDelivery{
+		Id:            uuid.NewSHA1(uuid.NameSpaceOID, []byte(event.Id+"/"+webhookId)).String(),
+		WebhookId:     webhookId,
+		VideoId:       event.VideoId,
+		Event:         event.Event,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// nonPublicPrefixes are ranges IsPublicAddress rejects beyond those the
+// netip predicates cover: shared address space (RFC 6598), IETF protocol
+// assignments, benchmarking and reserved blocks.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddress reports whether webhooks may be delivered to addr, which
+// excludes loopback, private, link-local (such as cloud metadata
+// endpoints), multicast and otherwise reserved addresses.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// AdminService lets support staff act on any owner's videos. Every call
+// names the acting admin and is recorded in the audit log.
+type AdminService interface {
+	ListVideos(ctx context.Context, actorId string, filter entity.VideoFilter) ([]entity.VideoFileResponse, error)
+	ForceStatus(ctx context.Context, actorId string, videoId string, status string, reason string) (*entity.VideoFileResponse, error)
+	Reprocess(ctx context.Context, actorId string, videoId string, intervalSeconds int) (*entity.VideoFileResponse, error)
+	Purge(ctx context.Context, actorId string, videoId string) error
+	GetAuditLog(ctx context.Context, limit int) ([]entity.AuditEntry, error)
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, ownerId string, name string, scopes []string, expiresAt *time.Time) (*entity.APIKeyResponse, error)
+	GetAPIKeys(ctx context.Context, ownerId string) ([]entity.APIKeyResponse, error)
+	Revoke(ctx context.Context, id string, ownerId string) error
+	// Authenticate resolves the owner of an API key, returning
+	// entity.ErrUnauthorized for unknown, revoked or expired keys.
+	Authenticate(ctx context.Context, key string) (*entity.Principal, error)
+}
@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, key entity.APIKey) error
+	FindByOwnerId(ctx context.Context, ownerId string) ([]entity.APIKey, error)
+	// FindByHash returns entity.ErrAPIKeyNotFound when no key has hash.
+	FindByHash(ctx context.Context, hash string) (*entity.APIKey, error)
+	// Revoke returns entity.ErrAPIKeyNotFound unless the owner has an
+	// unrevoked key with id.
+	Revoke(ctx context.Context, id string, ownerId string, at time.Time) error
+}
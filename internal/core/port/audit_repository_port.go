@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry entity.AuditEntry) error
+	FindRecent(ctx context.Context, limit int) ([]entity.AuditEntry, error)
+}
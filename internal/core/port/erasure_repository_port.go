@@ -0,0 +1,31 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type ErasureRepository interface {
+	// Request saves erasure unless its owner already has one pending or
+	// running, returning whichever erasure is in effect.
+	Request(ctx context.Context, erasure entity.OwnerErasure) (*entity.OwnerErasure, error)
+	// FindById returns entity.ErrErasureNotFound for unknown ids.
+	FindById(ctx context.Context, id string) (*entity.OwnerErasure, error)
+	// FindActive returns the owner's pending or running erasure, or
+	// entity.ErrErasureNotFound when there is none.
+	FindActive(ctx context.Context, ownerId string) (*entity.OwnerErasure, error)
+	// ClaimPending marks up to limit unfinished erasures as running and
+	// leases them for the given duration, so that concurrent runners don't
+	// pick the same ones.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]entity.OwnerErasure, error)
+	// EraseRecords deletes, in one transaction, the owner's rows other than
+	// their videos: webhooks, API keys, shares, usage, events and rate
+	// limits. It returns how many rows were deleted.
+	EraseRecords(ctx context.Context, ownerId string) (int64, error)
+	Complete(ctx context.Context, erasure entity.OwnerErasure) error
+	// Release records the erasure's progress and last error and makes it
+	// claimable again after retryIn.
+	Release(ctx context.Context, erasure entity.OwnerErasure, retryIn time.Duration) error
+}
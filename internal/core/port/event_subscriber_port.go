@@ -0,0 +1,7 @@
+package port
+
+import "github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+type EventSubscriber interface {
+	Subscribe(ownerId string) (<-chan entity.VideoEvent, func())
+}
@@ -0,0 +1,10 @@
+package port
+
+import "context"
+
+// HealthChecker reports whether a dependency needed to process videos is
+// reachable. Check must respect the deadline of ctx.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type HealthService interface {
+	Live() entity.HealthReport
+	Ready(ctx context.Context) entity.HealthReport
+}
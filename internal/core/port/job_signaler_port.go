@@ -0,0 +1,6 @@
+package port
+
+// JobSignaler broadcasts job control signals to every replica.
+type JobSignaler interface {
+	SignalCancel(videoId string) error
+}
@@ -0,0 +1,22 @@
+package port
+
+import (
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type OutboxRepository interface {
+	// ClaimPending leases up to limit unpublished messages for the given
+	// duration so that concurrent relays don't pick the same rows.
+	// Dead-lettered messages are never claimed.
+	ClaimPending(limit int, lease time.Duration) ([]entity.OutboxMessage, error)
+	// MarkDelivered records that the named sink accepted the message.
+	MarkDelivered(id string, sink string) error
+	MarkPublished(id string) error
+	MarkFailed(id string, lastError string, retryIn time.Duration) error
+	// MarkDead stops the message from being retried.
+	MarkDead(id string, lastError string) error
+	// PrunePublished deletes messages published before the given time.
+	PrunePublished(before time.Time) (int64, error)
+}
@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"io"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// OwnerService exports or erases everything stored for an owner. actorId is
+// the caller: the owner themselves, or an admin acting for them.
+type OwnerService interface {
+	// Export returns a zip archive of the owner's video records, status
+	// history and frame archives. The caller must close it.
+	Export(ctx context.Context, actorId string, ownerId string) (io.ReadCloser, error)
+	// Erase schedules the erasure of the owner's data, returning the
+	// erasure already in progress if there is one.
+	Erase(ctx context.Context, actorId string, ownerId string) (*entity.OwnerErasureResponse, error)
+	GetErasure(ctx context.Context, ownerId string, erasureId string) (*entity.OwnerErasureResponse, error)
+}
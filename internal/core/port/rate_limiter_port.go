@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type RateLimiter interface {
+	// Allow takes a token from key's bucket. When the bucket is empty it
+	// returns false and how long until the next token is available.
+	Allow(ctx context.Context, key string, limit entity.RateLimit) (bool, time.Duration, error)
+}
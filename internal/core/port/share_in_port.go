@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type ShareService interface {
+	Grant(ctx context.Context, videoId string, ownerId string, granteeId string, permission string) (*entity.ShareGrantResponse, error)
+	GetGrants(ctx context.Context, videoId string, ownerId string) ([]entity.ShareGrantResponse, error)
+	RevokeGrant(ctx context.Context, videoId string, grantId string, ownerId string) error
+	CreateLink(ctx context.Context, videoId string, ownerId string, version int, ttl time.Duration, maxDownloads int) (*entity.ShareLinkResponse, error)
+	GetLinks(ctx context.Context, videoId string, ownerId string) ([]entity.ShareLinkResponse, error)
+	RevokeLink(ctx context.Context, videoId string, linkId string, ownerId string) error
+	// DownloadShared serves the archive a signed link points to.
+	DownloadShared(ctx context.Context, token string) (io.Reader, error)
+}
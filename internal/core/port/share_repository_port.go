@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type ShareRepository interface {
+	// SaveGrant replaces any active grant of the video to the same grantee.
+	SaveGrant(ctx context.Context, grant entity.ShareGrant) error
+	// FindGrant returns the active grant of the video to granteeId, or
+	// entity.ErrShareNotFound.
+	FindGrant(ctx context.Context, videoId string, granteeId string) (*entity.ShareGrant, error)
+	FindGrantsByVideoId(ctx context.Context, videoId string) ([]entity.ShareGrant, error)
+	// RevokeGrant returns entity.ErrShareNotFound unless the video has an
+	// active grant with id.
+	RevokeGrant(ctx context.Context, videoId string, id string, at time.Time) error
+	SaveLink(ctx context.Context, link entity.ShareLink) error
+	FindLinksByVideoId(ctx context.Context, videoId string) ([]entity.ShareLink, error)
+	RevokeLink(ctx context.Context, videoId string, id string, at time.Time) error
+	// ConsumeLink counts a download against the link and returns it,
+	// or entity.ErrShareLinkUnavailable when it is expired, revoked or out
+	// of downloads.
+	ConsumeLink(ctx context.Context, id string, now time.Time) (*entity.ShareLink, error)
+	// ReleaseLink gives back a download counted by ConsumeLink that could
+	// not be served.
+	ReleaseLink(ctx context.Context, id string) error
+}
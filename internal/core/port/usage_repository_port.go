@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type UsageRepository interface {
+	// Add adds delta to delta.OwnerId's usage.
+	Add(ctx context.Context, delta entity.Usage) error
+	// Reserve adds delta like Add, but only if the result stays within
+	// quota, checking and adding in one step so concurrent reservations
+	// can't overshoot it. It returns entity.ErrQuotaExceeded otherwise.
+	Reserve(ctx context.Context, delta entity.Usage, quota entity.Quota) error
+	// Get returns the owner's usage, which is zero for owners with none
+	// recorded.
+	Get(ctx context.Context, ownerId string) (*entity.Usage, error)
+}
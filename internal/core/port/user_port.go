@@ -1,5 +1,14 @@
 package port
 
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
 type UserPort interface {
-	ValidateToken(token string, ownerId string) (bool, error)
+	// Authenticate resolves the caller a token was issued to, returning
+	// entity.ErrUnauthorized when the token is not valid.
+	Authenticate(ctx context.Context, token string) (*entity.Principal, error)
+	GetEmail(ctx context.Context, ownerId string) (string, error)
 }
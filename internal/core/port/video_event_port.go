@@ -0,0 +1,7 @@
+package port
+
+import "github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+type VideoEventNotifier interface {
+	Notify(event entity.VideoEvent) error
+}
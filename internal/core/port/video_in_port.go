@@ -1,6 +1,7 @@
 package port
 
 import (
+	"context"
 	"io"
 	"mime/multipart"
 
@@ -8,7 +9,14 @@ import (
 )
 
 type VideoService interface {
-	GenerateFrames(file multipart.File, header *multipart.FileHeader, ownerId string) error
-	GetVideos(ownerId string) ([]entity.VideoFileResponse, error)
-	DownloadZip(videoId string, ownerId string) (io.Reader, error)
+	GenerateFrames(ctx context.Context, file multipart.File, header *multipart.FileHeader, ownerId string) (*entity.VideoFileResponse, error)
+	GetVideos(ctx context.Context, ownerId string, filter entity.VideoFilter) (*entity.VideoPage, error)
+	DownloadZip(ctx context.Context, videoId string, ownerId string, version int) (io.Reader, error)
+	Cancel(ctx context.Context, videoId string, ownerId string) (*entity.VideoFileResponse, error)
+	// Delete removes the video with everything stored for it; deleting a
+	// video that no longer exists succeeds.
+	Delete(ctx context.Context, videoId string, ownerId string) error
+	Reprocess(ctx context.Context, videoId string, ownerId string, intervalSeconds int) (*entity.VideoFileResponse, error)
+	GetArchives(ctx context.Context, videoId string, ownerId string) ([]entity.VideoArchiveResponse, error)
+	GetUsage(ctx context.Context, ownerId string) (*entity.UsageResponse, error)
 }
@@ -0,0 +1,13 @@
+package port
+
+import "time"
+
+// VideoMetrics records what happens in the processing pipeline.
+type VideoMetrics interface {
+	UploadReceived(sizeBytes int64)
+	JobSucceeded(frameCount int)
+	JobFailed(errorClass string)
+	ObserveFFmpeg(duration time.Duration)
+	ObserveZip(duration time.Duration)
+	ObserveStorage(operation string, duration time.Duration)
+}
@@ -1,10 +1,53 @@
 package port
 
-import "github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
 
 type VideoRepository interface {
-	Save(video entity.VideoFile) error
-	FindById(id string) (*entity.VideoFile, error)
-	UpdateStatus(id string, status string) error
-	FindByOwnerId(ownerId string) ([]entity.VideoFile, error)
+	// Save inserts the video already claimed by workerId, so no other
+	// worker takes it for a stale job before its first heartbeat.
+	Save(ctx context.Context, video entity.VideoFile, workerId string) error
+	// FindById returns entity.ErrVideoNotFound for unknown ids.
+	FindById(ctx context.Context, id string) (*entity.VideoFile, error)
+	UpdateStatus(ctx context.Context, id string, status string) error
+	// TransitionStatus moves the video from the given status to event.Status
+	// and records the event in the outbox, returning entity.ErrStatusConflict
+	// when the video is no longer in that status.
+	TransitionStatus(ctx context.Context, event entity.VideoEvent, from string) error
+	FindByOwnerId(ctx context.Context, ownerId string) ([]entity.VideoFile, error)
+	// FindAll returns up to filter.Limit matching videos in filter's sort
+	// order, starting after filter.After.
+	FindAll(ctx context.Context, filter entity.VideoFilter) ([]entity.VideoFile, error)
+	// CountAll counts every video matching filter, ignoring its cursor and
+	// limit.
+	CountAll(ctx context.Context, filter entity.VideoFilter) (int, error)
+	// FindExpired returns up to limit finished videos whose latest archive,
+	// or upload when they have none, is older than before. An empty ownerId
+	// matches every owner except those in exceptOwnerIds.
+	FindExpired(ctx context.Context, ownerId string, exceptOwnerIds []string, before time.Time, limit int) ([]entity.VideoFile, error)
+	// Delete removes the video and its archive records.
+	Delete(ctx context.Context, id string) error
+	CountByStatus(ctx context.Context, status string) (int, error)
+	// CompleteProcessing records the archive and transitions the video from
+	// processing to event.Status in a single transaction.
+	CompleteProcessing(ctx context.Context, archive entity.VideoArchive, event entity.VideoEvent) error
+	FindArchives(ctx context.Context, videoId string) ([]entity.VideoArchive, error)
+	// FindEvents returns the status history of the owner's videos, oldest
+	// first, including videos that have since been deleted.
+	FindEvents(ctx context.Context, ownerId string) ([]entity.VideoEvent, error)
+	RecordAttempt(ctx context.Context, id string, attempts int, lastError string) error
+	// ClaimJob assigns the video to the worker and records the version and
+	// parameters being produced, so the job can be requeued elsewhere.
+	ClaimJob(ctx context.Context, video entity.VideoFile, workerId string) error
+	Heartbeat(ctx context.Context, workerId string) error
+	// ReleaseJobs hands the worker's unfinished jobs back for requeueing by
+	// marking them stale.
+	ReleaseJobs(ctx context.Context, workerId string) error
+	// ClaimStaleJobs reassigns to workerId up to limit processing videos
+	// whose worker has not sent a heartbeat within staleAfter.
+	ClaimStaleJobs(ctx context.Context, workerId string, staleAfter time.Duration, limit int) ([]entity.VideoFile, error)
 }
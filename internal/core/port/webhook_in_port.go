@@ -0,0 +1,10 @@
+package port
+
+import "github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+
+type WebhookService interface {
+	Register(ownerId string, url string) (*entity.WebhookResponse, error)
+	GetWebhooks(ownerId string) ([]entity.WebhookResponse, error)
+	GetDeliveries(webhookId string, ownerId string) ([]entity.WebhookDeliveryResponse, error)
+	Replay(deliveryId string, ownerId string) error
+}
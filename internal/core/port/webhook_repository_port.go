@@ -0,0 +1,29 @@
+package port
+
+import (
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type WebhookRepository interface {
+	Save(webhook entity.Webhook) error
+	FindByOwnerId(ownerId string) ([]entity.Webhook, error)
+	// FindById returns entity.ErrWebhookNotFound for unknown webhooks.
+	FindById(id string) (*entity.Webhook, error)
+	// SaveDelivery ignores deliveries that were already saved.
+	SaveDelivery(delivery entity.WebhookDelivery) error
+	UpdateDelivery(delivery entity.WebhookDelivery) error
+	// FindDeliveryById returns entity.ErrDeliveryNotFound for unknown
+	// deliveries.
+	FindDeliveryById(id string) (*entity.WebhookDelivery, error)
+	FindDeliveriesByWebhookId(webhookId string) ([]entity.WebhookDelivery, error)
+	// ClaimDueDeliveries leases up to limit pending deliveries whose next
+	// attempt is due, by pushing that attempt back by lease, so concurrent
+	// workers don't send the same delivery.
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+}
+
+type WebhookSender interface {
+	Send(url string, payload []byte, headers map[string]string) (int, error)
+}
@@ -1,10 +1,12 @@
 package port
 
 import (
+	"context"
 	"io"
 )
 
 type ZipRepository interface {
-	UploadFile(id string, file io.Reader) error
-	DownloadFile(id string) (io.Reader, error)
+	UploadFile(ctx context.Context, id string, file io.Reader) error
+	DownloadFile(ctx context.Context, id string) (io.Reader, error)
+	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+const (
+	defaultAdminListLimit = 100
+	maxAdminListLimit     = 1000
+)
+
+// forcibleStatuses are the statuses an admin may set directly. Moving a
+// video back to processing goes through Reprocess so a job is started.
+var forcibleStatuses = map[string]bool{
+	"ready_to_download": true,
+	"error":             true,
+	"cancelled":         true,
+	"dead_letter":       true,
+}
+
+type AdminUseCase struct {
+	Videos *VideoUseCase
+	Audit  port.AuditRepository
+	Logger *slog.Logger
+}
+
+func NewAdminUseCase(videos *VideoUseCase, audit port.AuditRepository) *AdminUseCase {
+	return &AdminUseCase{
+		Videos: videos,
+		Audit:  audit,
+		Logger: slog.Default(),
+	}
+}
+
+func (a *AdminUseCase) ListVideos(ctx context.Context, actorId string, filter entity.VideoFilter) ([]entity.VideoFileResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAdminListLimit
+	}
+	if filter.Limit > maxAdminListLimit {
+		filter.Limit = maxAdminListLimit
+	}
+
+	err := a.record(ctx, actorId, entity.AuditListVideos, "", map[string]string{
+		"owner_id": filter.OwnerId,
+		"status":   filter.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	videos, err := a.Videos.Repository.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetVideosResponse(videos), nil
+}
+
+// ForceStatus overrides a video's status, stopping its job if it was still
+// processing.
+func (a *AdminUseCase) ForceStatus(ctx context.Context, actorId string, videoId string, status string, reason string) (*entity.VideoFileResponse, error) {
+	if !forcibleStatuses[status] {
+		return nil, entity.ErrInvalidParameters
+	}
+
+	video, err := a.Videos.Repository.FindById(ctx, videoId)
+	if err != nil {
+		return nil, entity.ErrVideoNotFound
+	}
+
+	err = a.record(ctx, actorId, entity.AuditForceStatus, videoId, map[string]string{
+		"from":   video.Status,
+		"to":     status,
+		"reason": reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	from := video.Status
+	video.Status = status
+	err = a.Videos.Repository.TransitionStatus(ctx, entity.NewVideoEvent(*video, entity.EventVideoStatusForced), from)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == "processing" {
+		a.Videos.Abort(videoId)
+		if a.Videos.Signaler != nil {
+			err = a.Videos.Signaler.SignalCancel(videoId)
+			if err != nil {
+				a.Logger.ErrorContext(ctx, "Error signaling cancellation", logging.VideoID, videoId, "error", err)
+			}
+		}
+	}
+
+	response := GetVideosResponse([]entity.VideoFile{*video})[0]
+	return &response, nil
+}
+
+func (a *AdminUseCase) Reprocess(ctx context.Context, actorId string, videoId string, intervalSeconds int) (*entity.VideoFileResponse, error) {
+	video, err := a.Videos.Repository.FindById(ctx, videoId)
+	if err != nil {
+		return nil, entity.ErrVideoNotFound
+	}
+
+	err = a.record(ctx, actorId, entity.AuditReprocess, videoId, map[string]string{
+		"interval_seconds": strconv.Itoa(intervalSeconds),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Videos.Reprocess(ctx, videoId, video.OwnerId, intervalSeconds)
+}
+
+// Purge deletes a video with everything stored for it.
+func (a *AdminUseCase) Purge(ctx context.Context, actorId string, videoId string) error {
+	video, err := a.Videos.Repository.FindById(ctx, videoId)
+	if err != nil {
+		return entity.ErrVideoNotFound
+	}
+
+	err = a.record(ctx, actorId, entity.AuditPurge, videoId, map[string]string{
+		"owner_id": video.OwnerId,
+		"status":   video.Status,
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.Videos.purge(ctx, *video)
+}
+
+func (a *AdminUseCase) GetAuditLog(ctx context.Context, limit int) ([]entity.AuditEntry, error) {
+	if limit <= 0 {
+		limit = defaultAdminListLimit
+	}
+	if limit > maxAdminListLimit {
+		limit = maxAdminListLimit
+	}
+
+	return a.Audit.FindRecent(ctx, limit)
+}
+
+// record writes the audit entry before the action runs; an action that
+// can't be audited is not performed.
+func (a *AdminUseCase) record(ctx context.Context, actorId string, action string, targetId string, details map[string]string) error {
+	err := a.Audit.Record(ctx, entity.NewAuditEntry(actorId, action, targetId, details))
+	if err != nil {
+		a.Logger.ErrorContext(ctx, "Error recording audit entry", "action", action, "error", err)
+		return err
+	}
+
+	a.Logger.InfoContext(ctx, "Admin action", "actor_id", actorId, "action", action, "target_id", targetId)
+	return nil
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockAuditRepository struct {
+	entries []entity.AuditEntry
+	err     error
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, entry entity.AuditEntry) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditRepository) FindRecent(ctx context.Context, limit int) ([]entity.AuditEntry, error) {
+	return m.entries, nil
+}
+
+func newAdminUseCase(videos ...entity.VideoFile) (*AdminUseCase, *MockVideoRepository, *MockZipRepository, *MockAuditRepository) {
+	videoRepo := &MockVideoRepository{videos: videos}
+	zipRepo := &MockZipRepository{files: map[string]bytes.Buffer{}}
+	audit := &MockAuditRepository{}
+	return NewAdminUseCase(NewVideoUseCase(videoRepo, zipRepo), audit), videoRepo, zipRepo, audit
+}
+
+func TestAdmin_ListVideosAcrossOwners(t *testing.T) {
+	admin, _, _, audit := newAdminUseCase(
+		entity.VideoFile{Id: "video1", OwnerId: "123", Status: "error"},
+		entity.VideoFile{Id: "video2", OwnerId: "456", Status: "error"},
+		entity.VideoFile{Id: "video3", OwnerId: "456", Status: "ready_to_download"},
+	)
+
+	videos, err := admin.ListVideos(context.Background(), "admin1", entity.VideoFilter{Status: "error"})
+	if err != nil || len(videos) != 2 {
+		t.Fatalf("Expected both failed videos, got %+v %v", videos, err)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].ActorId != "admin1" || audit.entries[0].Action != entity.AuditListVideos {
+		t.Errorf("Expected the listing to be audited, got %+v", audit.entries)
+	}
+}
+
+func TestAdmin_ForceStatus(t *testing.T) {
+	admin, videoRepo, _, audit := newAdminUseCase(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "processing"})
+
+	_, err := admin.ForceStatus(context.Background(), "admin1", "video1", "processing", "")
+	if !errors.Is(err, entity.ErrInvalidParameters) {
+		t.Errorf("Expected processing to be rejected, got %v", err)
+	}
+
+	video, err := admin.ForceStatus(context.Background(), "admin1", "video1", "error", "stuck job")
+	if err != nil || video.Status != "error" || videoRepo.status("video1") != "error" {
+		t.Fatalf("Expected the status to be forced, got %+v %v", video, err)
+	}
+	if len(videoRepo.events) != 1 || videoRepo.events[0].Event != entity.EventVideoStatusForced {
+		t.Errorf("Expected a status forced event, got %+v", videoRepo.events)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Details["reason"] != "stuck job" || audit.entries[0].Details["from"] != "processing" {
+		t.Errorf("Expected the change to be audited, got %+v", audit.entries)
+	}
+}
+
+func TestAdmin_PurgeDeletesEverything(t *testing.T) {
+	admin, videoRepo, zipRepo, _ := newAdminUseCase(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "ready_to_download", SourceKey: "sources/video1.mp4"})
+	videoRepo.archives = []entity.VideoArchive{{VideoId: "video1", Version: 1, Key: "video1.zip"}, {VideoId: "video1", Version: 2, Key: "video1_v2.zip"}}
+	for _, key := range []string{"video1.zip", "video1_v2.zip", "sources/video1.mp4", "video2.zip"} {
+		zipRepo.files[key] = bytes.Buffer{}
+	}
+
+	err := admin.Purge(context.Background(), "admin1", "video1")
+	if err != nil {
+		t.Fatalf("Expected purge to succeed, got %v", err)
+	}
+
+	if len(videoRepo.videos) != 0 {
+		t.Errorf("Expected the video record to be deleted, got %+v", videoRepo.videos)
+	}
+	if _, kept := zipRepo.files["video2.zip"]; len(zipRepo.files) != 1 || !kept {
+		t.Errorf("Expected only other videos' files to remain, got %v", zipRepo.files)
+	}
+}
+
+func TestAdmin_UnauditedActionsAreNotPerformed(t *testing.T) {
+	admin, videoRepo, _, audit := newAdminUseCase(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "error"})
+	audit.err = errors.New("audit log unavailable")
+
+	err := admin.Purge(context.Background(), "admin1", "video1")
+	if err == nil || len(videoRepo.videos) != 1 {
+		t.Errorf("Expected purge to be refused, got %v with %d videos left", err, len(videoRepo.videos))
+	}
+}
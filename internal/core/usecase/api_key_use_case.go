@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+type APIKeyUseCase struct {
+	Repository port.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(repository port.APIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{Repository: repository}
+}
+
+func (a *APIKeyUseCase) Create(ctx context.Context, ownerId string, name string, scopes []string, expiresAt *time.Time) (*entity.APIKeyResponse, error) {
+	key, secret, err := entity.NewAPIKey(ownerId, name, scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.Repository.Save(ctx, *key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the hash is stored, so this is the one chance to see the key.
+	response := getAPIKeyResponse(*key)
+	response.Key = secret
+
+	return &response, nil
+}
+
+func (a *APIKeyUseCase) GetAPIKeys(ctx context.Context, ownerId string) ([]entity.APIKeyResponse, error) {
+	keys, err := a.Repository.FindByOwnerId(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entity.APIKeyResponse, 0)
+	for _, key := range keys {
+		response = append(response, getAPIKeyResponse(key))
+	}
+
+	return response, nil
+}
+
+func (a *APIKeyUseCase) Revoke(ctx context.Context, id string, ownerId string) error {
+	return a.Repository.Revoke(ctx, id, ownerId, time.Now().UTC())
+}
+
+func (a *APIKeyUseCase) Authenticate(ctx context.Context, secret string) (*entity.Principal, error) {
+	if !entity.IsAPIKey(secret) {
+		return nil, entity.ErrUnauthorized
+	}
+
+	key, err := a.Repository.FindByHash(ctx, entity.HashAPIKey(secret))
+	if errors.Is(err, entity.ErrAPIKeyNotFound) {
+		return nil, entity.ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.Active(time.Now()) {
+		return nil, entity.ErrUnauthorized
+	}
+
+	return &entity.Principal{OwnerId: key.OwnerId, APIKeyId: key.Id, Scopes: key.Scopes}, nil
+}
+
+func getAPIKeyResponse(key entity.APIKey) entity.APIKeyResponse {
+	return entity.APIKeyResponse{
+		Id:        key.Id,
+		OwnerId:   key.OwnerId,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		ExpiresAt: key.ExpiresAt,
+		RevokedAt: key.RevokedAt,
+		CreatedAt: key.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockAPIKeyRepository struct {
+	keys []entity.APIKey
+}
+
+func (m *MockAPIKeyRepository) Save(ctx context.Context, key entity.APIKey) error {
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *MockAPIKeyRepository) FindByOwnerId(ctx context.Context, ownerId string) ([]entity.APIKey, error) {
+	keys := []entity.APIKey{}
+	for _, key := range m.keys {
+		if key.OwnerId == ownerId {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) FindByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+	return nil, entity.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id string, ownerId string, at time.Time) error {
+	for i, key := range m.keys {
+		if key.Id == id && key.OwnerId == ownerId && key.RevokedAt == nil {
+			m.keys[i].RevokedAt = &at
+			return nil
+		}
+	}
+	return entity.ErrAPIKeyNotFound
+}
+
+func TestAPIKey_CreateAuthenticateRevoke(t *testing.T) {
+	repo := &MockAPIKeyRepository{}
+	useCase := NewAPIKeyUseCase(repo)
+
+	created, err := useCase.Create(context.Background(), "123", "pipeline", []string{entity.ScopeUpload, entity.ScopeRead}, nil)
+	if err != nil {
+		t.Fatalf("Expected key to be created, got %v", err)
+	}
+	if !entity.IsAPIKey(created.Key) || repo.keys[0].Hash == created.Key {
+		t.Fatalf("Expected a prefixed key stored only as a hash, got %+v", repo.keys[0])
+	}
+
+	principal, err := useCase.Authenticate(context.Background(), created.Key)
+	if err != nil || principal.OwnerId != "123" || !principal.HasScope(entity.ScopeUpload) || principal.HasScope(entity.ScopeDownload) {
+		t.Errorf("Expected a principal scoped to upload and read, got %+v %v", principal, err)
+	}
+
+	listed, _ := useCase.GetAPIKeys(context.Background(), "123")
+	if len(listed) != 1 || listed[0].Key != "" {
+		t.Errorf("Expected the listing to omit the key, got %+v", listed)
+	}
+
+	if err := useCase.Revoke(context.Background(), created.Id, "456"); !errors.Is(err, entity.ErrAPIKeyNotFound) {
+		t.Errorf("Expected another owner's revoke to fail, got %v", err)
+	}
+	if err := useCase.Revoke(context.Background(), created.Id, "123"); err != nil {
+		t.Fatalf("Expected revoke to succeed, got %v", err)
+	}
+	if _, err := useCase.Authenticate(context.Background(), created.Key); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKey_RejectsInvalidKeys(t *testing.T) {
+	repo := &MockAPIKeyRepository{}
+	useCase := NewAPIKeyUseCase(repo)
+
+	_, err := useCase.Create(context.Background(), "123", "", []string{"admin"}, nil)
+	if !errors.Is(err, entity.ErrInvalidParameters) {
+		t.Errorf("Expected unknown scope to be rejected, got %v", err)
+	}
+
+	expiry := time.Now().Add(time.Hour)
+	created, _ := useCase.Create(context.Background(), "123", "", []string{entity.ScopeRead}, &expiry)
+	past := time.Now().Add(-time.Minute)
+	repo.keys[0].ExpiresAt = &past
+
+	for _, key := range []string{created.Key, "vk_unknown", "not-a-key"} {
+		if _, err := useCase.Authenticate(context.Background(), key); !errors.Is(err, entity.ErrUnauthorized) {
+			t.Errorf("%s: expected ErrUnauthorized, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+// emailLinkTTL is how long the download link in a ready email stays valid.
+const emailLinkTTL = 7 * 24 * time.Hour
+
+var (
+	// headerBreaks are removed from user-supplied text placed in headers.
+	headerBreaks = strings.NewReplacer("\r", "", "\n", "")
+
+	readyEmailTemplate = template.Must(template.New("ready").Parse(`Hello,
+
+Your video "{{.VideoName}}" has finished processing.
+{{.FrameCount}} frames were extracted and packed into an archive.
+
+{{if .DownloadLink}}Download it here (the link is valid for 7 days): {{.DownloadLink}}
+{{else}}Sign in to download it from your videos.
+{{end}}`))
+
+	failedEmailTemplate = template.Must(template.New("failed").Parse(`Hello,
+
+Unfortunately we could not process your video "{{.VideoName}}".
+Please check the file and upload it again.
+
+Video id: {{.VideoId}}
+`))
+)
+
+type emailData struct {
+	entity.VideoEvent
+	DownloadLink string
+}
+
+// EmailUseCase sends a notification email to the owner when a video
+// finishes or fails processing.
+type EmailUseCase struct {
+	Mailer         port.Mailer
+	UserRepository port.UserPort
+	// Links issues the signed public link included in ready emails, since
+	// the owner can't follow an authenticated API route from their inbox.
+	Links port.ShareService
+}
+
+func NewEmailUseCase(mailer port.Mailer, userRepository port.UserPort, links port.ShareService) *EmailUseCase {
+	return &EmailUseCase{
+		Mailer:         mailer,
+		UserRepository: userRepository,
+		Links:          links,
+	}
+}
+
+func (e *EmailUseCase) Notify(event entity.VideoEvent) error {
+	if event.Event != entity.EventVideoReady && event.Event != entity.EventVideoFailed && event.Event != entity.EventVideoDeadLettered {
+		return nil
+	}
+
+	to, err := e.UserRepository.GetEmail(context.Background(), event.OwnerId)
+	if errors.Is(err, entity.ErrEmailNotFound) {
+		// Nobody to tell; retrying wouldn't change that.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	subject, body, err := e.render(event)
+	if err != nil {
+		return err
+	}
+
+	return e.Mailer.Send(to, subject, body)
+}
+
+func (e *EmailUseCase) render(event entity.VideoEvent) (string, string, error) {
+	var subject, link string
+	var tmpl *template.Template
+	name := headerBreaks.Replace(event.VideoName)
+	switch event.Event {
+	case entity.EventVideoReady:
+		subject = fmt.Sprintf("Your frames for %s are ready", name)
+		tmpl = readyEmailTemplate
+		var err error
+		link, err = e.downloadLink(event)
+		if err != nil {
+			return "", "", err
+		}
+	case entity.EventVideoFailed, entity.EventVideoDeadLettered:
+		subject = fmt.Sprintf("We could not process %s", name)
+		tmpl = failedEmailTemplate
+	default:
+		return "", "", fmt.Errorf("Unsupported event: %s", event.Event)
+	}
+
+	var body bytes.Buffer
+	err := tmpl.Execute(&body, emailData{
+		VideoEvent:   event,
+		DownloadLink: link,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return subject, body.String(), nil
+}
+
+// downloadLink creates a share link to the version the event announced.
+// It is empty when share links are disabled, in which case the email asks
+// the owner to sign in instead.
+func (e *EmailUseCase) downloadLink(event entity.VideoEvent) (string, error) {
+	if e.Links == nil {
+		return "", nil
+	}
+
+	link, err := e.Links.CreateLink(context.Background(), event.VideoId, event.OwnerId, event.Version, emailLinkTTL, 0)
+	if errors.Is(err, entity.ErrShareLinksDisabled) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return link.Url, nil
+}
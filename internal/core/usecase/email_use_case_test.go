@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockMailer struct {
+	to      string
+	subject string
+	body    string
+}
+
+func (m *MockMailer) Send(to string, subject string, body string) error {
+	m.to = to
+	m.subject = subject
+	m.body = body
+	return nil
+}
+
+type MockUserPort struct {
+	email string
+	err   error
+}
+
+func (m *MockUserPort) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	return &entity.Principal{OwnerId: "123"}, nil
+}
+
+func (m *MockUserPort) GetEmail(ctx context.Context, ownerId string) (string, error) {
+	return m.email, m.err
+}
+
+func TestEmailNotify_Ready(t *testing.T) {
+	mailer := &MockMailer{}
+	links, shares := newShareUseCase()
+	useCase := NewEmailUseCase(mailer, &MockUserPort{email: "user@example.com"}, links)
+
+	err := useCase.Notify(entity.VideoEvent{
+		Event:      entity.EventVideoReady,
+		VideoId:    "video1",
+		OwnerId:    "123",
+		VideoName:  "holiday.mp4",
+		FrameCount: 42,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if mailer.to != "user@example.com" {
+		t.Errorf("Expected email to user@example.com, got %s", mailer.to)
+	}
+	if !strings.Contains(mailer.subject, "holiday.mp4") {
+		t.Errorf("Expected subject to mention the video name, got %s", mailer.subject)
+	}
+	for _, expected := range []string{"42 frames", "https://videos.example.com/shared/"} {
+		if !strings.Contains(mailer.body, expected) {
+			t.Errorf("Expected body to contain %q, got %q", expected, mailer.body)
+		}
+	}
+	if len(shares.links) != 1 || shares.links[0].VideoId != "video1" {
+		t.Errorf("Expected a share link to the video to be issued, got %+v", shares.links)
+	}
+}
+
+func TestEmailNotify_ReadyWithoutShareLinks(t *testing.T) {
+	mailer := &MockMailer{}
+	links, _ := newShareUseCase()
+	links.Secret = nil
+	useCase := NewEmailUseCase(mailer, &MockUserPort{email: "user@example.com"}, links)
+
+	err := useCase.Notify(entity.VideoEvent{Event: entity.EventVideoReady, VideoId: "video1", OwnerId: "123", VideoName: "holiday.mp4"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if strings.Contains(mailer.body, "http") || !strings.Contains(mailer.body, "Sign in") {
+		t.Errorf("Expected the owner to be asked to sign in, got %q", mailer.body)
+	}
+}
+
+func TestEmailNotify_StripsLineBreaksFromSubject(t *testing.T) {
+	mailer := &MockMailer{}
+	useCase := NewEmailUseCase(mailer, &MockUserPort{email: "user@example.com"}, nil)
+
+	err := useCase.Notify(entity.VideoEvent{Event: entity.EventVideoFailed, VideoId: "video1", VideoName: "a\r\nBcc: victim@example.com\r\n.mp4"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if strings.ContainsAny(mailer.subject, "\r\n") {
+		t.Errorf("Expected no line breaks in the subject, got %q", mailer.subject)
+	}
+}
+
+func TestEmailNotify_Failed(t *testing.T) {
+	mailer := &MockMailer{}
+	useCase := NewEmailUseCase(mailer, &MockUserPort{email: "user@example.com"}, nil)
+
+	err := useCase.Notify(entity.VideoEvent{Event: entity.EventVideoFailed, VideoId: "video1", VideoName: "holiday.mp4"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(mailer.body, "could not process") {
+		t.Errorf("Expected failure body, got %q", mailer.body)
+	}
+}
+
+func TestEmailNotify_SkipsOwnersWithoutEmail(t *testing.T) {
+	mailer := &MockMailer{}
+	useCase := NewEmailUseCase(mailer, &MockUserPort{err: entity.ErrEmailNotFound}, nil)
+
+	err := useCase.Notify(entity.VideoEvent{Event: entity.EventVideoFailed, VideoId: "video1", OwnerId: "123"})
+	if err != nil || mailer.to != "" {
+		t.Errorf("Expected no email and no error, got %q %v", mailer.to, err)
+	}
+}
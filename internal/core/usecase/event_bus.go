@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"sync"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+const subscriberBuffer = 16
+
+// EventBus fans video events out to the owners currently subscribed on this
+// replica, e.g. through the server-sent events endpoint.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan entity.VideoEvent]struct{}
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[string]map[chan entity.VideoEvent]struct{}),
+	}
+}
+
+func (b *EventBus) Subscribe(ownerId string) (<-chan entity.VideoEvent, func()) {
+	ch := make(chan entity.VideoEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[ownerId] == nil {
+		b.subscribers[ownerId] = make(map[chan entity.VideoEvent]struct{})
+	}
+	b.subscribers[ownerId][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, exists := b.subscribers[ownerId][ch]; !exists {
+			return
+		}
+		delete(b.subscribers[ownerId], ch)
+		if len(b.subscribers[ownerId]) == 0 {
+			delete(b.subscribers, ownerId)
+		}
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// Notify never blocks: slow subscribers simply miss events.
+func (b *EventBus) Notify(event entity.VideoEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[event.OwnerId] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+const (
+	defaultHealthTimeout = 2 * time.Second
+	shutdownCheck        = "shutdown"
+)
+
+// HealthUseCase aggregates the dependency checks used by the readiness probe.
+// Draining reports whether the instance is shutting down, in which case it is
+// never ready regardless of its dependencies.
+type HealthUseCase struct {
+	Checkers []port.HealthChecker
+	Draining func() bool
+	Timeout  time.Duration
+}
+
+func NewHealthUseCase(draining func() bool, checkers ...port.HealthChecker) *HealthUseCase {
+	return &HealthUseCase{
+		Checkers: checkers,
+		Draining: draining,
+		Timeout:  defaultHealthTimeout,
+	}
+}
+
+func (h *HealthUseCase) Live() entity.HealthReport {
+	return entity.HealthReport{Status: entity.HealthStatusUp}
+}
+
+// Ready runs every check concurrently, each bounded by Timeout, and reports
+// down if any of them failed.
+func (h *HealthUseCase) Ready(ctx context.Context) entity.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	report := entity.HealthReport{
+		Status: entity.HealthStatusUp,
+		Checks: make(map[string]entity.HealthCheckResult, len(h.Checkers)+1),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range h.Checkers {
+		wg.Add(1)
+		go func(checker port.HealthChecker) {
+			defer wg.Done()
+			result := runCheck(ctx, checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.Name()] = result
+		}(checker)
+	}
+	wg.Wait()
+
+	if h.Draining != nil && h.Draining() {
+		report.Checks[shutdownCheck] = entity.HealthCheckResult{Status: entity.HealthStatusDown, Error: entity.ErrShuttingDown.Error()}
+	}
+
+	for _, result := range report.Checks {
+		if result.Status != entity.HealthStatusUp {
+			report.Status = entity.HealthStatusDown
+		}
+	}
+
+	return report
+}
+
+func runCheck(ctx context.Context, checker port.HealthChecker) entity.HealthCheckResult {
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := entity.HealthCheckResult{
+		Status:    entity.HealthStatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = entity.HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockHealthChecker struct {
+	name string
+	err  error
+}
+
+func (c *MockHealthChecker) Name() string {
+	return c.name
+}
+
+func (c *MockHealthChecker) Check(ctx context.Context) error {
+	return c.err
+}
+
+func TestReady_AllChecksUp(t *testing.T) {
+	healthUseCase := NewHealthUseCase(func() bool { return false },
+		&MockHealthChecker{name: "postgres"},
+		&MockHealthChecker{name: "ffmpeg"},
+	)
+
+	report := healthUseCase.Ready(context.Background())
+
+	if !report.Healthy() || len(report.Checks) != 2 {
+		t.Errorf("Expected healthy report with 2 checks, got %+v", report)
+	}
+}
+
+func TestReady_FailingDependency(t *testing.T) {
+	healthUseCase := NewHealthUseCase(nil,
+		&MockHealthChecker{name: "postgres"},
+		&MockHealthChecker{name: "object_storage", err: fmt.Errorf("bucket not found")},
+	)
+
+	report := healthUseCase.Ready(context.Background())
+
+	if report.Healthy() {
+		t.Fatal("Expected unhealthy report")
+	}
+	if report.Checks["postgres"].Status != entity.HealthStatusUp {
+		t.Errorf("Expected postgres up, got %+v", report.Checks["postgres"])
+	}
+	if report.Checks["object_storage"].Error != "bucket not found" {
+		t.Errorf("Expected object storage error, got %+v", report.Checks["object_storage"])
+	}
+}
+
+func TestReady_DownWhileDraining(t *testing.T) {
+	healthUseCase := NewHealthUseCase(func() bool { return true }, &MockHealthChecker{name: "postgres"})
+
+	report := healthUseCase.Ready(context.Background())
+
+	if report.Healthy() || report.Checks[shutdownCheck].Status != entity.HealthStatusDown {
+		t.Errorf("Expected shutdown to fail readiness, got %+v", report)
+	}
+	if !healthUseCase.Live().Healthy() {
+		t.Error("Expected liveness to stay up while draining")
+	}
+}
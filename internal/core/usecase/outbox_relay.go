@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+const (
+	defaultRelayInterval    = time.Second
+	defaultRelayBatchSize   = 50
+	defaultRelayLease       = time.Minute
+	defaultRelayMaxAttempts = 20
+	defaultRelayRetention   = 30 * 24 * time.Hour
+	maxRelayBackoff         = 10 * time.Minute
+	relayPruneInterval      = time.Hour
+)
+
+// OutboxSink is a named destination for outbox messages. The name is
+// recorded against every message the sink accepts, so it must stay stable
+// across releases.
+type OutboxSink struct {
+	Name     string
+	Notifier port.VideoEventNotifier
+}
+
+// OutboxRelay publishes committed outbox messages to every registered sink.
+// Each sink receives a message once: when one sink fails, the message is
+// retried only for the sinks that haven't accepted it yet. Messages still
+// failing after MaxAttempts are dead-lettered, and published messages are
+// pruned once they are older than Retention.
+type OutboxRelay struct {
+	Repository  port.OutboxRepository
+	Sinks       []OutboxSink
+	Interval    time.Duration
+	BatchSize   int
+	Lease       time.Duration
+	MaxAttempts int
+	Retention   time.Duration
+	Logger      *slog.Logger
+}
+
+func NewOutboxRelay(repository port.OutboxRepository, sinks ...OutboxSink) *OutboxRelay {
+	return &OutboxRelay{
+		Repository:  repository,
+		Sinks:       sinks,
+		Interval:    defaultRelayInterval,
+		BatchSize:   defaultRelayBatchSize,
+		Lease:       defaultRelayLease,
+		MaxAttempts: defaultRelayMaxAttempts,
+		Retention:   defaultRelayRetention,
+		Logger:      slog.Default(),
+	}
+}
+
+func (o *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		_, err := o.RelayOnce()
+		if err != nil {
+			o.Logger.ErrorContext(ctx, "Error relaying outbox messages", "error", err)
+		}
+
+		if time.Since(pruned) >= relayPruneInterval {
+			pruned = time.Now()
+			_, err = o.Prune()
+			if err != nil {
+				o.Logger.ErrorContext(ctx, "Error pruning outbox messages", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes a single batch and returns how many messages were
+// successfully published.
+func (o *OutboxRelay) RelayOnce() (int, error) {
+	messages, err := o.Repository.ClaimPending(o.BatchSize, o.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, message := range messages {
+		err = o.publish(message)
+		if err != nil {
+			if message.Attempts+1 >= o.MaxAttempts {
+				o.Logger.Error("Dead-lettering outbox message", "message_id", message.Id, logging.VideoID, message.VideoId, "event", message.Event, "attempts", message.Attempts+1, "error", err)
+				o.Repository.MarkDead(message.Id, err.Error())
+				continue
+			}
+
+			o.Logger.Warn("Error publishing outbox message", "message_id", message.Id, logging.VideoID, message.VideoId, "event", message.Event, "attempts", message.Attempts, "error", err)
+			o.Repository.MarkFailed(message.Id, err.Error(), o.backoff(message.Attempts))
+			continue
+		}
+
+		err = o.Repository.MarkPublished(message.Id)
+		if err != nil {
+			continue
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// Prune deletes published messages older than the retention period and
+// returns how many were removed.
+func (o *OutboxRelay) Prune() (int64, error) {
+	pruned, err := o.Repository.PrunePublished(time.Now().Add(-o.Retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if pruned > 0 {
+		o.Logger.Info("Pruned published outbox messages", "count", pruned)
+	}
+	return pruned, nil
+}
+
+// publish hands the message to every sink that hasn't accepted it yet,
+// recording each acceptance as it happens.
+func (o *OutboxRelay) publish(message entity.OutboxMessage) error {
+	var event entity.VideoEvent
+	err := json.Unmarshal(message.Payload, &event)
+	if err != nil {
+		return err
+	}
+
+	for _, sink := range o.Sinks {
+		if slices.Contains(message.Delivered, sink.Name) {
+			continue
+		}
+
+		err = sink.Notifier.Notify(event)
+		if err != nil {
+			return err
+		}
+
+		err = o.Repository.MarkDelivered(message.Id, sink.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *OutboxRelay) backoff(attempts int) time.Duration {
+	backoff := o.Interval * time.Duration(1<<min(attempts, 16))
+	if backoff > maxRelayBackoff {
+		return maxRelayBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockOutboxRepository struct {
+	messages  []entity.OutboxMessage
+	published map[string]bool
+	failed    map[string]string
+	dead      map[string]string
+}
+
+func (r *MockOutboxRepository) ClaimPending(limit int, lease time.Duration) ([]entity.OutboxMessage, error) {
+	var result []entity.OutboxMessage
+	for _, m := range r.messages {
+		if !r.published[m.Id] && r.dead[m.Id] == "" && len(result) < limit {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func (r *MockOutboxRepository) MarkDelivered(id string, sink string) error {
+	for i, m := range r.messages {
+		if m.Id == id {
+			r.messages[i].Delivered = append(r.messages[i].Delivered, sink)
+		}
+	}
+	return nil
+}
+
+func (r *MockOutboxRepository) MarkPublished(id string) error {
+	r.published[id] = true
+	return nil
+}
+
+func (r *MockOutboxRepository) MarkFailed(id string, lastError string, retryIn time.Duration) error {
+	r.failed[id] = lastError
+	for i, m := range r.messages {
+		if m.Id == id {
+			r.messages[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (r *MockOutboxRepository) MarkDead(id string, lastError string) error {
+	r.dead[id] = lastError
+	return nil
+}
+
+func (r *MockOutboxRepository) PrunePublished(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type MockSink struct {
+	events []entity.VideoEvent
+	err    error
+}
+
+func (s *MockSink) Notify(event entity.VideoEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func newOutboxMessage(t *testing.T, event entity.VideoEvent) entity.OutboxMessage {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	return entity.OutboxMessage{Id: event.Id, VideoId: event.VideoId, Event: event.Event, Payload: payload}
+}
+
+func TestRelayOnce_PublishesToAllSinks(t *testing.T) {
+	event := entity.NewVideoEvent(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "ready_to_download"}, entity.EventVideoReady)
+	repo := &MockOutboxRepository{
+		messages:  []entity.OutboxMessage{newOutboxMessage(t, event)},
+		published: map[string]bool{},
+		failed:    map[string]string{},
+		dead:      map[string]string{},
+	}
+	first, second := &MockSink{}, &MockSink{}
+	relay := NewOutboxRelay(repo, OutboxSink{Name: "first", Notifier: first}, OutboxSink{Name: "second", Notifier: second})
+
+	published, err := relay.RelayOnce()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if published != 1 || !repo.published[event.Id] {
+		t.Errorf("Expected message to be published, got %d", published)
+	}
+	if len(first.events) != 1 || len(second.events) != 1 || first.events[0].VideoId != "video1" {
+		t.Errorf("Expected every sink to receive the event, got %+v %+v", first.events, second.events)
+	}
+}
+
+func TestRelayOnce_KeepsMessageWhenSinkFails(t *testing.T) {
+	event := entity.NewVideoEvent(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "error"}, entity.EventVideoFailed)
+	repo := &MockOutboxRepository{
+		messages:  []entity.OutboxMessage{newOutboxMessage(t, event)},
+		published: map[string]bool{},
+		failed:    map[string]string{},
+		dead:      map[string]string{},
+	}
+	relay := NewOutboxRelay(repo, OutboxSink{Name: "sink", Notifier: &MockSink{err: fmt.Errorf("sink down")}})
+
+	published, err := relay.RelayOnce()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if published != 0 || repo.published[event.Id] {
+		t.Error("Expected message to stay unpublished")
+	}
+	if repo.failed[event.Id] != "sink down" {
+		t.Errorf("Expected failure to be recorded, got %q", repo.failed[event.Id])
+	}
+}
+
+func TestRelayOnce_RetriesOnlyFailedSinks(t *testing.T) {
+	event := entity.NewVideoEvent(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "ready_to_download"}, entity.EventVideoReady)
+	repo := &MockOutboxRepository{
+		messages:  []entity.OutboxMessage{newOutboxMessage(t, event)},
+		published: map[string]bool{},
+		failed:    map[string]string{},
+		dead:      map[string]string{},
+	}
+	first, second := &MockSink{}, &MockSink{err: fmt.Errorf("sink down")}
+	relay := NewOutboxRelay(repo, OutboxSink{Name: "first", Notifier: first}, OutboxSink{Name: "second", Notifier: second})
+
+	relay.RelayOnce()
+	second.err = nil
+	published, _ := relay.RelayOnce()
+
+	if published != 1 || !repo.published[event.Id] {
+		t.Fatalf("Expected the retry to publish the message, got %d", published)
+	}
+	if len(first.events) != 1 || len(second.events) != 1 {
+		t.Errorf("Expected each sink to receive the event once, got %d and %d", len(first.events), len(second.events))
+	}
+}
+
+func TestRelayOnce_DeadLettersAfterMaxAttempts(t *testing.T) {
+	event := entity.NewVideoEvent(entity.VideoFile{Id: "video1", OwnerId: "123", Status: "error"}, entity.EventVideoFailed)
+	repo := &MockOutboxRepository{
+		messages:  []entity.OutboxMessage{newOutboxMessage(t, event)},
+		published: map[string]bool{},
+		failed:    map[string]string{},
+		dead:      map[string]string{},
+	}
+	relay := NewOutboxRelay(repo, OutboxSink{Name: "sink", Notifier: &MockSink{err: fmt.Errorf("sink down")}})
+	relay.MaxAttempts = 3
+
+	for i := 0; i < 5; i++ {
+		relay.RelayOnce()
+	}
+
+	if repo.dead[event.Id] != "sink down" || repo.messages[0].Attempts != 2 {
+		t.Errorf("Expected the message to be dead-lettered on its third attempt, got %q after %d", repo.dead[event.Id], repo.messages[0].Attempts)
+	}
+}
+
+func TestEventBus_DeliversToOwnerSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	events, unsubscribe := bus.Subscribe("123")
+	defer unsubscribe()
+	other, unsubscribeOther := bus.Subscribe("456")
+	defer unsubscribeOther()
+
+	bus.Notify(entity.VideoEvent{OwnerId: "123", VideoId: "video1"})
+
+	select {
+	case event := <-events:
+		if event.VideoId != "video1" {
+			t.Errorf("Expected video1, got %s", event.VideoId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected subscriber to receive the event")
+	}
+
+	select {
+	case event := <-other:
+		t.Errorf("Expected no event for another owner, got %+v", event)
+	default:
+	}
+}
@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+const (
+	erasureBatchSize = 10
+	// erasureLease is how long a runner holds an erasure before another
+	// may take it over; erasing is idempotent, so an overrun only repeats
+	// work.
+	erasureLease      = 15 * time.Minute
+	erasureRetryDelay = time.Minute
+)
+
+// exportedVideo is a video record as written to an owner's export.
+type exportedVideo struct {
+	Id              string            `json:"id"`
+	Name            string            `json:"name"`
+	Status          string            `json:"status"`
+	SourceBytes     int64             `json:"source_bytes"`
+	Version         int               `json:"version"`
+	IntervalSeconds int               `json:"interval_seconds"`
+	Attempts        int               `json:"attempts"`
+	LastError       string            `json:"last_error,omitempty"`
+	Archives        []exportedArchive `json:"archives"`
+}
+
+type exportedArchive struct {
+	Version         int       `json:"version"`
+	FrameCount      int       `json:"frame_count"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	SizeBytes       int64     `json:"size_bytes"`
+	CreatedAt       time.Time `json:"created_at"`
+	// File is the archive's path within the export, empty when the video
+	// has expired.
+	File string `json:"file,omitempty"`
+}
+
+// OwnerUseCase exports and erases everything stored for an owner. Admins
+// acting on someone else's data are recorded in the audit log.
+type OwnerUseCase struct {
+	Videos     *VideoUseCase
+	Repository port.ErasureRepository
+	Audit      port.AuditRepository
+	Logger     *slog.Logger
+	wake       chan struct{}
+}
+
+func NewOwnerUseCase(videos *VideoUseCase, repository port.ErasureRepository, audit port.AuditRepository) *OwnerUseCase {
+	return &OwnerUseCase{
+		Videos:     videos,
+		Repository: repository,
+		Audit:      audit,
+		Logger:     slog.Default(),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Export loads the owner's records up front, so failures are reported
+// before anything is written, then streams the archive as it is read.
+func (o *OwnerUseCase) Export(ctx context.Context, actorId string, ownerId string) (io.ReadCloser, error) {
+	err := o.record(ctx, actorId, entity.AuditExportOwner, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	videos, err := o.Videos.Repository.FindByOwnerId(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]exportedVideo, 0, len(videos))
+	files := map[string]string{}
+	for _, video := range videos {
+		archives, err := o.Videos.Repository.FindArchives(ctx, video.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		record := exportedVideo{
+			Id:              video.Id,
+			Name:            video.Name,
+			Status:          video.Status,
+			SourceBytes:     video.SourceSize,
+			Version:         video.Version,
+			IntervalSeconds: video.IntervalSeconds,
+			Attempts:        video.Attempts,
+			LastError:       video.LastError,
+			Archives:        make([]exportedArchive, 0, len(archives)),
+		}
+		for _, archive := range archives {
+			exportedArchive := exportedArchive{
+				Version:         archive.Version,
+				FrameCount:      archive.FrameCount,
+				IntervalSeconds: archive.IntervalSeconds,
+				SizeBytes:       archive.Size,
+				CreatedAt:       archive.CreatedAt,
+			}
+			if video.Status != "expired" {
+				exportedArchive.File = fmt.Sprintf("archives/%s/%s", video.Id, archive.Key)
+				files[exportedArchive.File] = archive.Key
+			}
+			record.Archives = append(record.Archives, exportedArchive)
+		}
+		exported = append(exported, record)
+	}
+
+	events, err := o.Videos.Repository.FindEvents(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(o.writeExport(ctx, writer, exported, events, files))
+	}()
+
+	o.Logger.InfoContext(ctx, "Exporting owner data", logging.OwnerID, ownerId, "videos", len(exported), "files", len(files))
+	return reader, nil
+}
+
+func (o *OwnerUseCase) writeExport(ctx context.Context, w io.Writer, videos []exportedVideo, events []entity.VideoEvent, files map[string]string) error {
+	archive := zip.NewWriter(w)
+
+	err := writeZipJSON(archive, "videos.json", videos)
+	if err != nil {
+		return err
+	}
+	err = writeZipJSON(archive, "events.json", events)
+	if err != nil {
+		return err
+	}
+
+	// Sorted so the same data always produces the same archive.
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := files[name]
+		file, err := o.Videos.downloadFile(ctx, key)
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", key, err)
+		}
+
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+		if err != nil {
+			return fmt.Errorf("copying %s: %w", key, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+func writeZipJSON(archive *zip.Writer, name string, body interface{}) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(body)
+}
+
+func (o *OwnerUseCase) Erase(ctx context.Context, actorId string, ownerId string) (*entity.OwnerErasureResponse, error) {
+	err := o.record(ctx, actorId, entity.AuditEraseOwner, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	erasure, err := o.Repository.Request(ctx, entity.NewOwnerErasure(ownerId, actorId))
+	if err != nil {
+		return nil, err
+	}
+
+	o.Logger.InfoContext(ctx, "Erasure requested", logging.OwnerID, ownerId, "erasure_id", erasure.Id, "actor_id", actorId)
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	response := getErasureResponse(*erasure)
+	return &response, nil
+}
+
+func (o *OwnerUseCase) GetErasure(ctx context.Context, ownerId string, erasureId string) (*entity.OwnerErasureResponse, error) {
+	erasure, err := o.Repository.FindById(ctx, erasureId)
+	if err != nil {
+		return nil, err
+	}
+	if erasure.OwnerId != ownerId {
+		return nil, entity.ErrErasureNotFound
+	}
+
+	response := getErasureResponse(*erasure)
+	return &response, nil
+}
+
+// RunErasures carries out requested erasures every interval, or as soon as
+// one is requested on this replica, until ctx is cancelled. Erasures cut
+// short are picked up again once their lease runs out.
+func (o *OwnerUseCase) RunErasures(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := o.ProcessErasures(ctx)
+		if err != nil {
+			o.Logger.ErrorContext(ctx, "Error processing erasures", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// ProcessErasures claims and carries out pending erasures until none are
+// left, returning how many were completed. An erasure that fails is
+// released to be retried later.
+func (o *OwnerUseCase) ProcessErasures(ctx context.Context) (int, error) {
+	completed := 0
+	for ctx.Err() == nil {
+		erasures, err := o.Repository.ClaimPending(ctx, erasureBatchSize, erasureLease)
+		if err != nil {
+			return completed, err
+		}
+
+		for _, erasure := range erasures {
+			err := o.erase(ctx, &erasure)
+			if err != nil {
+				o.Logger.ErrorContext(ctx, "Error erasing owner data", logging.OwnerID, erasure.OwnerId, "erasure_id", erasure.Id, "error", err)
+				erasure.LastError = err.Error()
+				err = o.Repository.Release(ctx, erasure, erasureRetryDelay)
+				if err != nil {
+					return completed, err
+				}
+				continue
+			}
+
+			now := time.Now().UTC()
+			erasure.CompletedAt = &now
+			err = o.Repository.Complete(ctx, erasure)
+			if err != nil {
+				return completed, err
+			}
+			completed++
+			o.Logger.InfoContext(ctx, "Erasure completed", logging.OwnerID, erasure.OwnerId, "erasure_id", erasure.Id,
+				"videos", erasure.VideosErased, "records", erasure.RecordsErased)
+		}
+
+		if len(erasures) < erasureBatchSize {
+			break
+		}
+	}
+
+	return completed, nil
+}
+
+// erase removes the owner's API keys and shares first, so no new work
+// reaches their videos through them, then the videos with their objects,
+// and finally the rows, such as usage, that purging the videos updated.
+func (o *OwnerUseCase) erase(ctx context.Context, erasure *entity.OwnerErasure) error {
+	erased, err := o.Repository.EraseRecords(ctx, erasure.OwnerId)
+	erasure.RecordsErased += erased
+	if err != nil {
+		return err
+	}
+
+	for {
+		videos, err := o.Videos.Repository.FindByOwnerId(ctx, erasure.OwnerId)
+		if err != nil {
+			return err
+		}
+		if len(videos) == 0 {
+			break
+		}
+
+		for _, video := range videos {
+			err := o.Videos.purge(ctx, video)
+			if err != nil {
+				return err
+			}
+			erasure.VideosErased++
+		}
+	}
+
+	erased, err = o.Repository.EraseRecords(ctx, erasure.OwnerId)
+	erasure.RecordsErased += erased
+	return err
+}
+
+// record audits admins acting on another owner's data; an action that
+// can't be audited is not performed. Owners acting on their own data
+// aren't audited.
+func (o *OwnerUseCase) record(ctx context.Context, actorId string, action string, ownerId string) error {
+	if actorId == ownerId || o.Audit == nil {
+		return nil
+	}
+
+	err := o.Audit.Record(ctx, entity.NewAuditEntry(actorId, action, ownerId, nil))
+	if err != nil {
+		o.Logger.ErrorContext(ctx, "Error recording audit entry", "action", action, "error", err)
+	}
+
+	return err
+}
+
+func getErasureResponse(erasure entity.OwnerErasure) entity.OwnerErasureResponse {
+	return entity.OwnerErasureResponse{
+		Id:            erasure.Id,
+		OwnerId:       erasure.OwnerId,
+		RequestedBy:   erasure.RequestedBy,
+		Status:        erasure.Status,
+		VideosErased:  erasure.VideosErased,
+		RecordsErased: erasure.RecordsErased,
+		LastError:     erasure.LastError,
+		RequestedAt:   erasure.RequestedAt,
+		CompletedAt:   erasure.CompletedAt,
+	}
+}
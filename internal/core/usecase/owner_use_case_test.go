@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockErasureRepository struct {
+	mu       sync.Mutex
+	erasures []entity.OwnerErasure
+	// records is how many rows EraseRecords finds for each owner.
+	records map[string]int64
+	err     error
+}
+
+func (m *MockErasureRepository) Request(ctx context.Context, erasure entity.OwnerErasure) (*entity.OwnerErasure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.erasures {
+		if e.OwnerId == erasure.OwnerId && e.Status != entity.ErasureCompleted {
+			return &e, nil
+		}
+	}
+	m.erasures = append(m.erasures, erasure)
+	return &erasure, nil
+}
+
+func (m *MockErasureRepository) FindById(ctx context.Context, id string) (*entity.OwnerErasure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.erasures {
+		if e.Id == id {
+			return &e, nil
+		}
+	}
+	return nil, entity.ErrErasureNotFound
+}
+
+func (m *MockErasureRepository) FindActive(ctx context.Context, ownerId string) (*entity.OwnerErasure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.erasures {
+		if e.OwnerId == ownerId && e.Status != entity.ErasureCompleted {
+			return &e, nil
+		}
+	}
+	return nil, entity.ErrErasureNotFound
+}
+
+func (m *MockErasureRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]entity.OwnerErasure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []entity.OwnerErasure
+	for i, e := range m.erasures {
+		if e.Status == entity.ErasurePending && len(claimed) < limit {
+			m.erasures[i].Status = entity.ErasureRunning
+			m.erasures[i].Attempts++
+			claimed = append(claimed, m.erasures[i])
+		}
+	}
+	return claimed, nil
+}
+
+func (m *MockErasureRepository) EraseRecords(ctx context.Context, ownerId string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return 0, m.err
+	}
+	erased := m.records[ownerId]
+	delete(m.records, ownerId)
+	return erased, nil
+}
+
+func (m *MockErasureRepository) Complete(ctx context.Context, erasure entity.OwnerErasure) error {
+	erasure.Status = entity.ErasureCompleted
+	m.update(erasure)
+	return nil
+}
+
+func (m *MockErasureRepository) Release(ctx context.Context, erasure entity.OwnerErasure, retryIn time.Duration) error {
+	erasure.Status = entity.ErasurePending
+	m.update(erasure)
+	return nil
+}
+
+func (m *MockErasureRepository) update(erasure entity.OwnerErasure) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, e := range m.erasures {
+		if e.Id == erasure.Id {
+			m.erasures[i] = erasure
+		}
+	}
+}
+
+func TestExport_IncludesRecordsHistoryAndArchives(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Name: "talk.mp4", Status: "ready_to_download", Version: 1},
+			{OwnerId: "123", Id: "video2", Status: "expired", Version: 1},
+			{OwnerId: "456", Id: "video3", Status: "ready_to_download", Version: 1},
+		},
+		archives: []entity.VideoArchive{
+			{VideoId: "video1", Version: 1, Key: "video1.zip", FrameCount: 12},
+			{VideoId: "video2", Version: 1, Key: "video2.zip"},
+			{VideoId: "video3", Version: 1, Key: "video3.zip"},
+		},
+		events: []entity.VideoEvent{
+			{Event: entity.EventVideoReady, VideoId: "video1", OwnerId: "123"},
+			{Event: entity.EventVideoReady, VideoId: "video3", OwnerId: "456"},
+		},
+	}
+	owners := NewOwnerUseCase(NewVideoUseCase(videoRepo, archiveZipRepository{}), &MockErasureRepository{}, &MockAuditRepository{})
+
+	export, err := owners.Export(context.Background(), "123", "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer export.Close()
+
+	body, err := io.ReadAll(export)
+	if err != nil {
+		t.Fatalf("Expected the export to be written, got %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive, got %v", err)
+	}
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Error opening %s: %v", file.Name, err)
+		}
+		files[file.Name], _ = io.ReadAll(reader)
+		reader.Close()
+	}
+	if len(files) != 3 || string(files["archives/video1/video1.zip"]) != "zip:video1.zip" {
+		t.Fatalf("Expected the records and the unexpired archive only, got %v", files)
+	}
+
+	var videos []exportedVideo
+	err = json.Unmarshal(files["videos.json"], &videos)
+	if err != nil || len(videos) != 2 || videos[0].Name != "talk.mp4" || videos[0].Archives[0].FrameCount != 12 {
+		t.Errorf("Expected the owner's video records, got %+v %v", videos, err)
+	}
+	if len(videos) == 2 && (len(videos[1].Archives) != 1 || videos[1].Archives[0].File != "") {
+		t.Errorf("Expected the expired archive to be listed without a file, got %+v", videos[1].Archives)
+	}
+
+	var events []entity.VideoEvent
+	err = json.Unmarshal(files["events.json"], &events)
+	if err != nil || len(events) != 1 || events[0].VideoId != "video1" {
+		t.Errorf("Expected the owner's status history, got %+v %v", events, err)
+	}
+}
+
+func TestErase_RemovesEverythingAndLeavesReceipt(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "processing", SourceKey: "sources/video1.mp4"},
+			{OwnerId: "123", Id: "video2", Status: "ready_to_download"},
+			{OwnerId: "456", Id: "video3", Status: "ready_to_download"},
+		},
+		archives: []entity.VideoArchive{{VideoId: "video2", Version: 1, Key: "video2.zip"}},
+	}
+	zipRepo := &MockZipRepository{files: map[string]bytes.Buffer{}}
+	for _, key := range []string{"sources/video1.mp4", "video2.zip", "video3.zip"} {
+		zipRepo.files[key] = bytes.Buffer{}
+	}
+	erasures := &MockErasureRepository{records: map[string]int64{"123": 4, "456": 2}}
+	audit := &MockAuditRepository{}
+	owners := NewOwnerUseCase(NewVideoUseCase(videoRepo, zipRepo), erasures, audit)
+
+	requested, err := owners.Erase(context.Background(), "123", "123")
+	if err != nil || requested.Status != entity.ErasurePending {
+		t.Fatalf("Expected a pending erasure, got %+v %v", requested, err)
+	}
+	again, err := owners.Erase(context.Background(), "123", "123")
+	if err != nil || again.Id != requested.Id {
+		t.Errorf("Expected the erasure in progress to be returned, got %+v %v", again, err)
+	}
+
+	completed, err := owners.ProcessErasures(context.Background())
+	if err != nil || completed != 1 {
+		t.Fatalf("Expected 1 erasure to complete, got %d %v", completed, err)
+	}
+
+	if len(videoRepo.videos) != 1 || videoRepo.videos[0].Id != "video3" {
+		t.Errorf("Expected only the other owner's video to remain, got %+v", videoRepo.videos)
+	}
+	if _, kept := zipRepo.files["video3.zip"]; len(zipRepo.files) != 1 || !kept {
+		t.Errorf("Expected only the other owner's files to remain, got %v", zipRepo.files)
+	}
+	if erasures.records["456"] != 2 {
+		t.Errorf("Expected the other owner's records to be kept, got %v", erasures.records)
+	}
+
+	receipt, err := owners.GetErasure(context.Background(), "123", requested.Id)
+	if err != nil || receipt.Status != entity.ErasureCompleted || receipt.CompletedAt == nil || receipt.VideosErased != 2 || receipt.RecordsErased != 4 {
+		t.Errorf("Expected a completion receipt, got %+v %v", receipt, err)
+	}
+	_, err = owners.GetErasure(context.Background(), "456", requested.Id)
+	if !errors.Is(err, entity.ErrErasureNotFound) {
+		t.Errorf("Expected other owners not to see the erasure, got %v", err)
+	}
+	if len(audit.entries) != 0 {
+		t.Errorf("Expected owners acting on their own data not to be audited, got %+v", audit.entries)
+	}
+}
+
+func TestErase_BlocksNewWork(t *testing.T) {
+	videoRepo := &MockVideoRepository{videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "error", SourceKey: "sources/video1.mp4"}}}
+	erasures := &MockErasureRepository{}
+	videos := NewVideoUseCase(videoRepo, &MockZipRepository{files: map[string]bytes.Buffer{}})
+	videos.Erasures = erasures
+	owners := NewOwnerUseCase(videos, erasures, &MockAuditRepository{})
+
+	owners.Erase(context.Background(), "123", "123")
+
+	file := &mockMultipartFile{Reader: bytes.NewReader([]byte("dummy video content"))}
+	_, err := videos.GenerateFrames(context.Background(), file, &multipart.FileHeader{Filename: "video.mp4"}, "123")
+	if !errors.Is(err, entity.ErrErasureInProgress) {
+		t.Errorf("Expected uploads to be refused, got %v", err)
+	}
+	_, err = videos.Reprocess(context.Background(), "video1", "123", 0)
+	if !errors.Is(err, entity.ErrErasureInProgress) {
+		t.Errorf("Expected reprocessing to be refused, got %v", err)
+	}
+
+	owners.ProcessErasures(context.Background())
+	_, err = videos.GenerateFrames(context.Background(), file, &multipart.FileHeader{Filename: "video.mp4"}, "456")
+	if errors.Is(err, entity.ErrErasureInProgress) {
+		t.Errorf("Expected other owners to be unaffected, got %v", err)
+	}
+}
+
+func TestErase_FailureIsRetried(t *testing.T) {
+	videoRepo := &MockVideoRepository{videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "error"}}}
+	erasures := &MockErasureRepository{err: errors.New("database unavailable")}
+	owners := NewOwnerUseCase(NewVideoUseCase(videoRepo, &MockZipRepository{files: map[string]bytes.Buffer{}}), erasures, &MockAuditRepository{})
+
+	requested, _ := owners.Erase(context.Background(), "123", "123")
+	completed, err := owners.ProcessErasures(context.Background())
+	if err != nil || completed != 0 {
+		t.Fatalf("Expected the erasure not to complete, got %d %v", completed, err)
+	}
+
+	erasure, _ := owners.GetErasure(context.Background(), "123", requested.Id)
+	if erasure.Status != entity.ErasurePending || erasure.LastError != "database unavailable" {
+		t.Errorf("Expected the erasure to be released with its error, got %+v", erasure)
+	}
+
+	erasures.err = nil
+	completed, err = owners.ProcessErasures(context.Background())
+	if err != nil || completed != 1 || len(videoRepo.videos) != 0 {
+		t.Errorf("Expected the retry to complete the erasure, got %d %v %+v", completed, err, videoRepo.videos)
+	}
+}
+
+func TestErase_AdminsAreAudited(t *testing.T) {
+	videoRepo := &MockVideoRepository{}
+	erasures := &MockErasureRepository{}
+	audit := &MockAuditRepository{}
+	owners := NewOwnerUseCase(NewVideoUseCase(videoRepo, archiveZipRepository{}), erasures, audit)
+
+	_, err := owners.Erase(context.Background(), "admin1", "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != entity.AuditEraseOwner || audit.entries[0].TargetId != "123" {
+		t.Errorf("Expected the erasure to be audited, got %+v", audit.entries)
+	}
+
+	audit.err = errors.New("audit log unavailable")
+	_, err = owners.Export(context.Background(), "admin1", "456")
+	if err == nil {
+		t.Error("Expected unaudited exports to be refused")
+	}
+	_, err = owners.Erase(context.Background(), "admin1", "456")
+	if err == nil || len(erasures.erasures) != 1 {
+		t.Errorf("Expected unaudited erasures not to be requested, got %v %+v", err, erasures.erasures)
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	recoveryBatchSize    = 50
+	interruptedJobReason = "processing was interrupted and the source video was not retained"
+)
+
+// scratchFile matches the files the pipeline leaves in the working directory:
+// <id>.mp4 sources, frame_<id>_NNNN.png frames (or the older frame_NNNN.png)
+// and output_<id>[_vN].zip archives.
+var scratchFile = regexp.MustCompile(`^([0-9a-f-]{36}\.mp4|frame_([0-9a-f-]{36}_)?\d+\.png|output_[0-9a-f-]{36}(_v\d+)?\.zip)$`)
+
+// NewWorkerId returns an id unique to this process, prefixed with host when
+// it is set so operators can tell which replica ran a job. A restarted
+// container keeps its host name; were the host the whole id, the new process
+// would keep its predecessor's jobs alive through its own heartbeats.
+func NewWorkerId(host string) string {
+	if host == "" {
+		return uuid.New().String()
+	}
+	return host + "-" + uuid.New().String()
+}
+
+// RunHeartbeat refreshes the heartbeat of every job running on this worker
+// until ctx is cancelled.
+func (v *VideoUseCase) RunHeartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := v.Repository.Heartbeat(ctx, v.WorkerId)
+			if err != nil {
+				v.Logger.ErrorContext(ctx, "Error sending job heartbeat", "error", err)
+			}
+		}
+	}
+}
+
+// SweepScratchFiles removes the files a previous run left in workDir. It
+// can't tell them apart from those of uploads and jobs in progress, so it
+// must finish before this worker accepts uploads or starts any job.
+func (v *VideoUseCase) SweepScratchFiles(ctx context.Context, workDir string) {
+	removed, err := RemoveScratchFiles(workDir)
+	if err != nil {
+		v.Logger.ErrorContext(ctx, "Error removing scratch files", "error", err)
+	}
+	v.Logger.InfoContext(ctx, "Removed orphaned scratch files", "files", removed)
+}
+
+// RunReconciler recovers jobs abandoned by dead workers at startup and then
+// periodically, until ctx is cancelled.
+func (v *VideoUseCase) RunReconciler(ctx context.Context, staleAfter time.Duration) {
+	ticker := time.NewTicker(staleAfter)
+	defer ticker.Stop()
+
+	for {
+		_, err := v.Recover(ctx, staleAfter)
+		if err != nil {
+			v.Logger.ErrorContext(ctx, "Error recovering stale jobs", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Recover claims processing jobs whose worker stopped sending heartbeats.
+// Jobs whose source is still in storage are requeued on this worker, the
+// others are marked as failed. It returns how many jobs were requeued.
+func (v *VideoUseCase) Recover(ctx context.Context, staleAfter time.Duration) (int, error) {
+	requeued := 0
+	for !v.Draining() {
+		videos, err := v.Repository.ClaimStaleJobs(ctx, v.WorkerId, staleAfter, recoveryBatchSize)
+		if err != nil {
+			return requeued, err
+		}
+
+		for i := range videos {
+			video := videos[i]
+			if video.SourceKey == "" {
+				video.LastError = interruptedJobReason
+				v.Metrics.JobFailed(ErrorClassPermanent)
+				v.fail(ctx, &video, entity.EventVideoFailed, "error", errors.New(interruptedJobReason))
+				continue
+			}
+
+			v.Logger.InfoContext(ctx, "Requeueing interrupted job", logging.VideoID, video.Id, "attempts", video.Attempts)
+			if video.IntervalSeconds == 0 {
+				video.IntervalSeconds = secondsInterval
+			}
+			jobCtx := v.startJob(ctx, video.Id)
+			go v.process(jobCtx, &video)
+			requeued++
+		}
+
+		if len(videos) < recoveryBatchSize {
+			break
+		}
+	}
+
+	return requeued, nil
+}
+
+// RemoveScratchFiles deletes pipeline files left in dir by a previous run and
+// returns how many were removed.
+func RemoveScratchFiles(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !scratchFile.MatchString(entry.Name()) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			slog.Error("Error deleting file", "file", entry.Name(), "error", err)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
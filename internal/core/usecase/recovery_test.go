@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+func TestRecover_RequeuesOrFailsStaleJobs(t *testing.T) {
+	retained := entity.VideoFile{OwnerId: "123", Id: "retained", Status: "processing", SourceKey: "sources/retained.mp4", Version: 1}
+	discarded := entity.VideoFile{OwnerId: "123", Id: "discarded", Status: "processing", Version: 1}
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{retained, discarded},
+		stale:  []entity.VideoFile{retained, discarded},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	ran := make(chan string, 1)
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error {
+		ran <- videoFile.Id
+		videoFile.Status = "ready_to_download"
+		return videoRepo.CompleteProcessing(ctx, entity.NewVideoArchive(*videoFile), entity.NewVideoEvent(*videoFile, entity.EventVideoReady))
+	}
+
+	requeued, err := videoUseCase.Recover(context.Background(), time.Minute)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requeued != 1 {
+		t.Errorf("Expected 1 requeued job, got %d", requeued)
+	}
+
+	select {
+	case id := <-ran:
+		if id != "retained" {
+			t.Errorf("Expected retained video to be requeued, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected requeued job to run")
+	}
+
+	if videoRepo.status("discarded") != "error" {
+		t.Errorf("Expected video without source to fail, got %s", videoRepo.status("discarded"))
+	}
+	videoRepo.mu.Lock()
+	lastError := videoRepo.videos[1].LastError
+	videoRepo.mu.Unlock()
+	if lastError != interruptedJobReason {
+		t.Errorf("Expected failure reason to be recorded, got %q", lastError)
+	}
+}
+
+// heartbeatVideoRepository tracks which worker holds each job and when it
+// last sent a heartbeat, on a clock the test advances.
+type heartbeatVideoRepository struct {
+	*MockVideoRepository
+	now        time.Time
+	workers    map[string]string
+	heartbeats map[string]time.Time
+}
+
+func newHeartbeatVideoRepository(videos ...entity.VideoFile) *heartbeatVideoRepository {
+	return &heartbeatVideoRepository{
+		MockVideoRepository: &MockVideoRepository{videos: videos},
+		now:                 time.Now(),
+		workers:             map[string]string{},
+		heartbeats:          map[string]time.Time{},
+	}
+}
+
+func (r *heartbeatVideoRepository) Save(ctx context.Context, video entity.VideoFile, workerId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.videos = append(r.videos, video)
+	r.workers[video.Id] = workerId
+	r.heartbeats[video.Id] = r.now
+	return nil
+}
+
+func (r *heartbeatVideoRepository) ClaimJob(ctx context.Context, video entity.VideoFile, workerId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workers[video.Id] = workerId
+	r.heartbeats[video.Id] = r.now
+	return nil
+}
+
+func (r *heartbeatVideoRepository) Heartbeat(ctx context.Context, workerId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, worker := range r.workers {
+		if worker == workerId {
+			r.heartbeats[id] = r.now
+		}
+	}
+	return nil
+}
+
+func (r *heartbeatVideoRepository) ClaimStaleJobs(ctx context.Context, workerId string, staleAfter time.Duration, limit int) ([]entity.VideoFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stale []entity.VideoFile
+	for _, video := range r.videos {
+		if video.Status == "processing" && r.heartbeats[video.Id].Before(r.now.Add(-staleAfter)) && len(stale) < limit {
+			r.workers[video.Id] = workerId
+			r.heartbeats[video.Id] = r.now
+			stale = append(stale, video)
+		}
+	}
+	return stale, nil
+}
+
+func (r *heartbeatVideoRepository) worker(videoId string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.workers[videoId]
+}
+
+func TestRecover_RestartedWorkerRecoversItsPredecessorsJobs(t *testing.T) {
+	video := entity.VideoFile{OwnerId: "123", Id: "video1", Status: "processing", SourceKey: "sources/video1.mp4", Version: 1}
+	videoRepo := newHeartbeatVideoRepository(video)
+	crashed := NewWorkerId("pod-0")
+	videoRepo.ClaimJob(context.Background(), video, crashed)
+
+	// The container restarts under the same host name long after the crash.
+	videoRepo.now = videoRepo.now.Add(time.Hour)
+	restarted := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	restarted.WorkerId = NewWorkerId("pod-0")
+	if restarted.WorkerId == crashed {
+		t.Fatalf("Expected a new worker id, got the predecessor's %s", crashed)
+	}
+	ran := make(chan string, 1)
+	restarted.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error {
+		ran <- videoFile.Id
+		return errors.New("stopped")
+	}
+
+	videoRepo.Heartbeat(context.Background(), restarted.WorkerId)
+	requeued, err := restarted.Recover(context.Background(), time.Minute)
+	if err != nil || requeued != 1 {
+		t.Fatalf("Expected the predecessor's job to be requeued, got %d %v", requeued, err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the requeued job to run")
+	}
+	if worker := videoRepo.worker("video1"); worker != restarted.WorkerId {
+		t.Errorf("Expected the job to be held by %s, got %s", restarted.WorkerId, worker)
+	}
+}
+
+func TestRecover_LeavesNewUploadsAlone(t *testing.T) {
+	videoRepo := newHeartbeatVideoRepository()
+	uploader := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	release := make(chan struct{})
+	defer close(release)
+	uploader.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error {
+		<-release
+		return nil
+	}
+
+	file := &mockMultipartFile{Reader: bytes.NewReader([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"))}
+	uploaded, err := uploader.GenerateFrames(context.Background(), file, &multipart.FileHeader{Filename: "video.mp4"}, "123")
+	if err != nil {
+		t.Fatalf("Expected the upload to be accepted, got %v", err)
+	}
+	defer os.Remove(uploaded.Id + ".mp4")
+
+	other := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	requeued, err := other.Recover(context.Background(), time.Minute)
+	if err != nil || requeued != 0 {
+		t.Errorf("Expected the new upload not to be taken as stale, got %d %v", requeued, err)
+	}
+	if worker := videoRepo.worker(uploaded.Id); worker != uploader.WorkerId {
+		t.Errorf("Expected the upload to be held by %s, got %s", uploader.WorkerId, worker)
+	}
+}
+
+func TestRemoveScratchFiles(t *testing.T) {
+	dir := t.TempDir()
+	id := "908ba06a-a155-46da-96bd-a9db58cbc56b"
+	scratch := []string{id + ".mp4", "frame_" + id + "_0001.png", "frame_0001.png", "output_" + id + ".zip", "output_" + id + "_v2.zip"}
+	kept := []string{"main.go", "notes.mp4", "frame_notes.png"}
+	for _, name := range append(scratch, kept...) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	removed, err := RemoveScratchFiles(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if removed != len(scratch) {
+		t.Errorf("Expected %d files removed, got %d", len(scratch), removed)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	sort.Strings(kept)
+	if len(remaining) != len(kept) {
+		t.Errorf("Expected %v to remain, got %v", kept, remaining)
+	}
+}
+
+func TestSweepScratchFiles_RemovesOrphans(t *testing.T) {
+	dir := t.TempDir()
+	id := "908ba06a-a155-46da-96bd-a9db58cbc56b"
+	for _, name := range []string{id + ".mp4", "frame_" + id + "_0001.png", "frame_" + id + "_0002.png", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+	videoUseCase := NewVideoUseCase(&MockVideoRepository{}, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	// It returns only once the sweep is done, so startup can wait on it.
+	videoUseCase.SweepScratchFiles(context.Background(), dir)
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "notes.txt" {
+		t.Errorf("Expected only notes.txt to remain, got %v", entries)
+	}
+}
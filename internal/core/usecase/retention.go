@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+const expiryBatchSize = 100
+
+// RunJanitor expires videos past their retention period every interval,
+// until ctx is cancelled.
+func (v *VideoUseCase) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := v.Expire(ctx)
+		if err != nil {
+			v.Logger.ErrorContext(ctx, "Error expiring videos", "error", err)
+		}
+		if expired > 0 {
+			v.Logger.InfoContext(ctx, "Expired videos", "videos", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Expire applies the retention policy once, first to the owners with a
+// period of their own and then to everyone else. It returns how many videos
+// were expired.
+func (v *VideoUseCase) Expire(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	expired := 0
+	overridden := make([]string, 0, len(v.Retention.Owners))
+
+	for ownerId, period := range v.Retention.Owners {
+		overridden = append(overridden, ownerId)
+		if period <= 0 {
+			continue
+		}
+
+		n, err := v.expireBefore(ctx, ownerId, nil, now.Add(-period))
+		expired += n
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	if v.Retention.Default <= 0 {
+		return expired, nil
+	}
+
+	n, err := v.expireBefore(ctx, "", overridden, now.Add(-v.Retention.Default))
+	return expired + n, err
+}
+
+func (v *VideoUseCase) expireBefore(ctx context.Context, ownerId string, exceptOwnerIds []string, before time.Time) (int, error) {
+	expired := 0
+	for !v.Draining() {
+		videos, err := v.Repository.FindExpired(ctx, ownerId, exceptOwnerIds, before, expiryBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		// A failed video stays unexpired and would be found again, so the
+		// rest of the batch is still expired but the run stops after it.
+		var failed error
+		for _, video := range videos {
+			ok, err := v.expire(ctx, video)
+			if err != nil {
+				failed = err
+				continue
+			}
+			if ok {
+				expired++
+			}
+		}
+		if failed != nil {
+			return expired, failed
+		}
+
+		if len(videos) < expiryBatchSize {
+			break
+		}
+	}
+
+	return expired, nil
+}
+
+// expire deletes the video's objects and only then marks it expired, so a
+// video whose objects failed to delete is found again by the next run.
+// Losing the transition to a replica racing to expire it, or to a delete,
+// is not an error.
+func (v *VideoUseCase) expire(ctx context.Context, video entity.VideoFile) (bool, error) {
+	archiveBytes, err := v.deleteObjects(ctx, video)
+	if err != nil {
+		v.Logger.ErrorContext(ctx, "Error deleting expired video objects", logging.VideoID, video.Id, "error", err)
+		return false, err
+	}
+
+	from := video.Status
+	video.Status = "expired"
+	err = v.Repository.TransitionStatus(ctx, entity.NewVideoEvent(video, entity.EventVideoExpired), from)
+	if errors.Is(err, entity.ErrStatusConflict) || errors.Is(err, entity.ErrVideoNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	v.recordUsage(ctx, entity.Usage{OwnerId: video.OwnerId, SourceBytes: -video.SourceSize, ArchiveBytes: -archiveBytes})
+
+	return true, nil
+}
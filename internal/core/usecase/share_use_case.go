@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+)
+
+const (
+	defaultShareLinkTTL = 24 * time.Hour
+	maxShareLinkTTL     = 30 * 24 * time.Hour
+)
+
+type ShareUseCase struct {
+	Videos     *VideoUseCase
+	Repository port.ShareRepository
+	// Secret signs public links. Every replica must share it, and without
+	// one links can't be created or followed.
+	Secret []byte
+	// BaseURL is prepended to the path of new links.
+	BaseURL string
+
+	now func() time.Time
+}
+
+func NewShareUseCase(videos *VideoUseCase, repository port.ShareRepository, secret []byte, baseURL string) *ShareUseCase {
+	return &ShareUseCase{
+		Videos:     videos,
+		Repository: repository,
+		Secret:     secret,
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		now:        time.Now,
+	}
+}
+
+func (s *ShareUseCase) Grant(ctx context.Context, videoId string, ownerId string, granteeId string, permission string) (*entity.ShareGrantResponse, error) {
+	video, err := s.ownedVideo(ctx, videoId, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := entity.NewShareGrant(*video, granteeId, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.SaveGrant(ctx, *grant)
+	if err != nil {
+		return nil, err
+	}
+
+	response := getShareGrantResponse(*grant)
+	return &response, nil
+}
+
+func (s *ShareUseCase) GetGrants(ctx context.Context, videoId string, ownerId string) ([]entity.ShareGrantResponse, error) {
+	_, err := s.ownedVideo(ctx, videoId, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.Repository.FindGrantsByVideoId(ctx, videoId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entity.ShareGrantResponse, 0)
+	for _, grant := range grants {
+		response = append(response, getShareGrantResponse(grant))
+	}
+
+	return response, nil
+}
+
+func (s *ShareUseCase) RevokeGrant(ctx context.Context, videoId string, grantId string, ownerId string) error {
+	_, err := s.ownedVideo(ctx, videoId, ownerId)
+	if err != nil {
+		return err
+	}
+
+	return s.Repository.RevokeGrant(ctx, videoId, grantId, s.now().UTC())
+}
+
+// CreateLink creates a public link to the archive, valid for ttl (a day when
+// zero) and for up to maxDownloads downloads (unlimited when zero).
+func (s *ShareUseCase) CreateLink(ctx context.Context, videoId string, ownerId string, version int, ttl time.Duration, maxDownloads int) (*entity.ShareLinkResponse, error) {
+	if len(s.Secret) == 0 {
+		return nil, entity.ErrShareLinksDisabled
+	}
+	if ttl == 0 {
+		ttl = defaultShareLinkTTL
+	}
+	if ttl > maxShareLinkTTL {
+		return nil, entity.ErrInvalidParameters
+	}
+
+	video, err := s.ownedVideo(ctx, videoId, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := entity.NewShareLink(*video, version, ttl, maxDownloads)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.SaveLink(ctx, *link)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is only ever handed out here; listings show the link
+	// without it.
+	response := getShareLinkResponse(*link)
+	response.Url = s.BaseURL + "/shared/" + s.sign(link.Id, link.ExpiresAt)
+
+	return &response, nil
+}
+
+func (s *ShareUseCase) GetLinks(ctx context.Context, videoId string, ownerId string) ([]entity.ShareLinkResponse, error) {
+	_, err := s.ownedVideo(ctx, videoId, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.Repository.FindLinksByVideoId(ctx, videoId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entity.ShareLinkResponse, 0)
+	for _, link := range links {
+		response = append(response, getShareLinkResponse(link))
+	}
+
+	return response, nil
+}
+
+func (s *ShareUseCase) RevokeLink(ctx context.Context, videoId string, linkId string, ownerId string) error {
+	_, err := s.ownedVideo(ctx, videoId, ownerId)
+	if err != nil {
+		return err
+	}
+
+	return s.Repository.RevokeLink(ctx, videoId, linkId, s.now().UTC())
+}
+
+// DownloadShared verifies the link's signature and expiry before touching
+// the database, then counts the download and serves the archive on behalf
+// of the owner who created the link. Downloads that fail, e.g. because the
+// archive has expired, are given back to the link.
+func (s *ShareUseCase) DownloadShared(ctx context.Context, token string) (io.Reader, error) {
+	if len(s.Secret) == 0 {
+		return nil, entity.ErrShareLinksDisabled
+	}
+
+	linkId, expiresAt, ok := s.verify(token)
+	if !ok {
+		return nil, entity.ErrShareNotFound
+	}
+
+	now := s.now().UTC()
+	if !now.Before(expiresAt) {
+		return nil, entity.ErrShareLinkUnavailable
+	}
+
+	// Counting first keeps concurrent downloads within the limit.
+	link, err := s.Repository.ConsumeLink(ctx, linkId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.Videos.DownloadZip(ctx, link.VideoId, link.OwnerId, link.Version)
+	if err != nil {
+		// The repository logs failures; the download error is what matters.
+		s.Repository.ReleaseLink(context.WithoutCancel(ctx), link.Id)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ownedVideo only lets owners manage shares; grantees can't reshare.
+func (s *ShareUseCase) ownedVideo(ctx context.Context, videoId string, ownerId string) (*entity.VideoFile, error) {
+	video, err := s.Videos.Repository.FindById(ctx, videoId)
+	if err != nil || video.OwnerId != ownerId {
+		return nil, entity.ErrVideoNotFound
+	}
+
+	return video, nil
+}
+
+// sign returns "<payload>.<signature>", both base64url encoded, where the
+// payload is "<link id>.<expiry unix seconds>".
+func (s *ShareUseCase) sign(linkId string, expiresAt time.Time) string {
+	payload := []byte(linkId + "." + strconv.FormatInt(expiresAt.Unix(), 10))
+	encoding := base64.RawURLEncoding
+
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(s.mac(payload))
+}
+
+func (s *ShareUseCase) verify(token string) (string, time.Time, bool) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.mac(payload)) {
+		return "", time.Time{}, false
+	}
+
+	linkId, rawExpiry, ok := strings.Cut(string(payload), ".")
+	if !ok {
+		return "", time.Time{}, false
+	}
+	expiry, err := strconv.ParseInt(rawExpiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return linkId, time.Unix(expiry, 0).UTC(), true
+}
+
+func (s *ShareUseCase) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func getShareGrantResponse(grant entity.ShareGrant) entity.ShareGrantResponse {
+	return entity.ShareGrantResponse{
+		Id:         grant.Id,
+		VideoId:    grant.VideoId,
+		GranteeId:  grant.GranteeId,
+		Permission: grant.Permission,
+		CreatedAt:  grant.CreatedAt,
+		RevokedAt:  grant.RevokedAt,
+	}
+}
+
+func getShareLinkResponse(link entity.ShareLink) entity.ShareLinkResponse {
+	return entity.ShareLinkResponse{
+		Id:           link.Id,
+		VideoId:      link.VideoId,
+		Version:      link.Version,
+		ExpiresAt:    link.ExpiresAt,
+		MaxDownloads: link.MaxDownloads,
+		Downloads:    link.Downloads,
+		CreatedAt:    link.CreatedAt,
+		RevokedAt:    link.RevokedAt,
+	}
+}
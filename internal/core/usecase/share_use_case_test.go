@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockShareRepository struct {
+	grants []entity.ShareGrant
+	links  []entity.ShareLink
+}
+
+func (m *MockShareRepository) SaveGrant(ctx context.Context, grant entity.ShareGrant) error {
+	for i, g := range m.grants {
+		if g.VideoId == grant.VideoId && g.GranteeId == grant.GranteeId && g.RevokedAt == nil {
+			m.grants[i].Permission = grant.Permission
+			return nil
+		}
+	}
+	m.grants = append(m.grants, grant)
+	return nil
+}
+
+func (m *MockShareRepository) FindGrant(ctx context.Context, videoId string, granteeId string) (*entity.ShareGrant, error) {
+	for _, g := range m.grants {
+		if g.VideoId == videoId && g.GranteeId == granteeId && g.RevokedAt == nil {
+			return &g, nil
+		}
+	}
+	return nil, entity.ErrShareNotFound
+}
+
+func (m *MockShareRepository) FindGrantsByVideoId(ctx context.Context, videoId string) ([]entity.ShareGrant, error) {
+	grants := []entity.ShareGrant{}
+	for _, g := range m.grants {
+		if g.VideoId == videoId {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+func (m *MockShareRepository) RevokeGrant(ctx context.Context, videoId string, id string, at time.Time) error {
+	for i, g := range m.grants {
+		if g.Id == id && g.VideoId == videoId && g.RevokedAt == nil {
+			m.grants[i].RevokedAt = &at
+			return nil
+		}
+	}
+	return entity.ErrShareNotFound
+}
+
+func (m *MockShareRepository) SaveLink(ctx context.Context, link entity.ShareLink) error {
+	m.links = append(m.links, link)
+	return nil
+}
+
+func (m *MockShareRepository) FindLinksByVideoId(ctx context.Context, videoId string) ([]entity.ShareLink, error) {
+	links := []entity.ShareLink{}
+	for _, l := range m.links {
+		if l.VideoId == videoId {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (m *MockShareRepository) RevokeLink(ctx context.Context, videoId string, id string, at time.Time) error {
+	for i, l := range m.links {
+		if l.Id == id && l.VideoId == videoId && l.RevokedAt == nil {
+			m.links[i].RevokedAt = &at
+			return nil
+		}
+	}
+	return entity.ErrShareNotFound
+}
+
+func (m *MockShareRepository) ConsumeLink(ctx context.Context, id string, now time.Time) (*entity.ShareLink, error) {
+	for i, l := range m.links {
+		if l.Id != id {
+			continue
+		}
+		if l.RevokedAt != nil || !now.Before(l.ExpiresAt) || (l.MaxDownloads > 0 && l.Downloads >= l.MaxDownloads) {
+			return nil, entity.ErrShareLinkUnavailable
+		}
+		m.links[i].Downloads++
+		return &m.links[i], nil
+	}
+	return nil, entity.ErrShareLinkUnavailable
+}
+
+func (m *MockShareRepository) ReleaseLink(ctx context.Context, id string) error {
+	for i, l := range m.links {
+		if l.Id == id && l.Downloads > 0 {
+			m.links[i].Downloads--
+		}
+	}
+	return nil
+}
+
+// archiveZipRepository serves the same archive for every key.
+type archiveZipRepository struct{}
+
+func (archiveZipRepository) UploadFile(ctx context.Context, key string, file io.Reader) error {
+	return nil
+}
+
+func (archiveZipRepository) DownloadFile(ctx context.Context, key string) (io.Reader, error) {
+	return strings.NewReader("zip:" + key), nil
+}
+
+func (archiveZipRepository) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+func newShareUseCase() (*ShareUseCase, *MockShareRepository) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "ready_to_download"}},
+		archives: []entity.VideoArchive{
+			{VideoId: "video1", Version: 1, Key: "video1.zip"},
+			{VideoId: "video1", Version: 2, Key: "video1_v2.zip"},
+		},
+	}
+	shares := &MockShareRepository{}
+	videos := NewVideoUseCase(videoRepo, archiveZipRepository{})
+	videos.Shares = shares
+
+	return NewShareUseCase(videos, shares, []byte("link-secret"), "https://videos.example.com/"), shares
+}
+
+func TestShareGrant_EnforcedOnDownload(t *testing.T) {
+	useCase, _ := newShareUseCase()
+	ctx := context.Background()
+
+	_, err := useCase.Videos.DownloadZip(ctx, "video1", "456", 0)
+	if !errors.Is(err, entity.ErrVideoNotFound) {
+		t.Fatalf("Expected a stranger to be refused, got %v", err)
+	}
+
+	// Grantees can't reshare.
+	_, err = useCase.Grant(ctx, "video1", "456", "789", entity.ScopeRead)
+	if !errors.Is(err, entity.ErrVideoNotFound) {
+		t.Errorf("Expected only the owner to share, got %v", err)
+	}
+
+	_, err = useCase.Grant(ctx, "video1", "123", "456", "admin")
+	if !errors.Is(err, entity.ErrInvalidParameters) {
+		t.Errorf("Expected an unknown permission to be rejected, got %v", err)
+	}
+
+	grant, err := useCase.Grant(ctx, "video1", "123", "456", entity.ScopeRead)
+	if err != nil {
+		t.Fatalf("Expected grant to be created, got %v", err)
+	}
+
+	archives, err := useCase.Videos.GetArchives(ctx, "video1", "456")
+	if err != nil || len(archives) != 2 {
+		t.Errorf("Expected a read grant to list archives, got %v %v", archives, err)
+	}
+	_, err = useCase.Videos.DownloadZip(ctx, "video1", "456", 0)
+	if !errors.Is(err, entity.ErrVideoNotFound) {
+		t.Errorf("Expected a read grant not to allow downloads, got %v", err)
+	}
+
+	// Granting again upgrades the existing grant.
+	_, err = useCase.Grant(ctx, "video1", "123", "456", entity.ScopeDownload)
+	if err != nil {
+		t.Fatalf("Expected grant to be updated, got %v", err)
+	}
+	file, err := useCase.Videos.DownloadZip(ctx, "video1", "456", 0)
+	if err != nil {
+		t.Fatalf("Expected a download grant to allow downloads, got %v", err)
+	}
+	content, _ := io.ReadAll(file)
+	if string(content) != "zip:video1_v2.zip" {
+		t.Errorf("Expected the latest archive, got %q", content)
+	}
+
+	err = useCase.RevokeGrant(ctx, "video1", grant.Id, "123")
+	if err != nil {
+		t.Fatalf("Expected grant to be revoked, got %v", err)
+	}
+	_, err = useCase.Videos.DownloadZip(ctx, "video1", "456", 0)
+	if !errors.Is(err, entity.ErrVideoNotFound) {
+		t.Errorf("Expected a revoked grant to be refused, got %v", err)
+	}
+}
+
+func TestShareLink_SignedExpiringAndLimited(t *testing.T) {
+	useCase, _ := newShareUseCase()
+	ctx := context.Background()
+
+	link, err := useCase.CreateLink(ctx, "video1", "123", 1, time.Hour, 2)
+	if err != nil {
+		t.Fatalf("Expected link to be created, got %v", err)
+	}
+	token, ok := strings.CutPrefix(link.Url, "https://videos.example.com/shared/")
+	if !ok {
+		t.Fatalf("Expected a public url, got %q", link.Url)
+	}
+
+	for i := 0; i < 2; i++ {
+		file, err := useCase.DownloadShared(ctx, token)
+		if err != nil {
+			t.Fatalf("Expected download %d to succeed, got %v", i+1, err)
+		}
+		content, _ := io.ReadAll(file)
+		if string(content) != "zip:video1.zip" {
+			t.Errorf("Expected the pinned version, got %q", content)
+		}
+	}
+	_, err = useCase.DownloadShared(ctx, token)
+	if !errors.Is(err, entity.ErrShareLinkUnavailable) {
+		t.Errorf("Expected the download limit to be enforced, got %v", err)
+	}
+
+	// Tampering with the payload, e.g. to extend the expiry, breaks the signature.
+	payload, signature, _ := strings.Cut(token, ".")
+	forged := []string{"", token + "x", payload, "x" + payload + "." + signature}
+	for _, f := range forged {
+		_, err = useCase.DownloadShared(ctx, f)
+		if !errors.Is(err, entity.ErrShareNotFound) {
+			t.Errorf("Expected forged token %q to be rejected, got %v", f, err)
+		}
+	}
+
+	other := NewShareUseCase(useCase.Videos, useCase.Repository, []byte("another-secret"), "")
+	_, err = other.DownloadShared(ctx, token)
+	if !errors.Is(err, entity.ErrShareNotFound) {
+		t.Errorf("Expected a token signed with another secret to be rejected, got %v", err)
+	}
+}
+
+func TestShareLink_FailedDownloadsAreNotCounted(t *testing.T) {
+	useCase, shares := newShareUseCase()
+	ctx := context.Background()
+
+	link, err := useCase.CreateLink(ctx, "video1", "123", 0, time.Hour, 1)
+	if err != nil {
+		t.Fatalf("Expected link to be created, got %v", err)
+	}
+	token := link.Url[len("https://videos.example.com/shared/"):]
+	useCase.Videos.Repository.UpdateStatus(ctx, "video1", "expired")
+
+	for i := 0; i < 2; i++ {
+		_, err = useCase.DownloadShared(ctx, token)
+		if !errors.Is(err, entity.ErrVideoExpired) {
+			t.Errorf("Expected download %d to fail with ErrVideoExpired, got %v", i+1, err)
+		}
+	}
+	if shares.links[0].Downloads != 0 {
+		t.Errorf("Expected failed downloads not to use up the link, got %d", shares.links[0].Downloads)
+	}
+}
+
+func TestShareLink_ExpiryAndRevocation(t *testing.T) {
+	useCase, _ := newShareUseCase()
+	ctx := context.Background()
+
+	expiring, err := useCase.CreateLink(ctx, "video1", "123", 0, time.Minute, 0)
+	if err != nil {
+		t.Fatalf("Expected link to be created, got %v", err)
+	}
+	revoked, err := useCase.CreateLink(ctx, "video1", "123", 0, 0, 0)
+	if err != nil {
+		t.Fatalf("Expected link to be created, got %v", err)
+	}
+	err = useCase.RevokeLink(ctx, "video1", revoked.Id, "123")
+	if err != nil {
+		t.Fatalf("Expected link to be revoked, got %v", err)
+	}
+
+	_, err = useCase.DownloadShared(ctx, revoked.Url[len("https://videos.example.com/shared/"):])
+	if !errors.Is(err, entity.ErrShareLinkUnavailable) {
+		t.Errorf("Expected a revoked link to be refused, got %v", err)
+	}
+
+	useCase.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = useCase.DownloadShared(ctx, expiring.Url[len("https://videos.example.com/shared/"):])
+	if !errors.Is(err, entity.ErrShareLinkUnavailable) {
+		t.Errorf("Expected an expired link to be refused, got %v", err)
+	}
+
+	links, err := useCase.GetLinks(ctx, "video1", "123")
+	if err != nil || len(links) != 2 || links[0].Url != "" {
+		t.Errorf("Expected links to be listed without their tokens, got %+v %v", links, err)
+	}
+
+	_, err = useCase.CreateLink(ctx, "video1", "123", 0, 365*24*time.Hour, 0)
+	if !errors.Is(err, entity.ErrInvalidParameters) {
+		t.Errorf("Expected an overly long expiry to be rejected, got %v", err)
+	}
+
+	disabled := NewShareUseCase(useCase.Videos, useCase.Repository, nil, "")
+	_, err = disabled.CreateLink(ctx, "video1", "123", 0, 0, 0)
+	if !errors.Is(err, entity.ErrShareLinksDisabled) {
+		t.Errorf("Expected links to be disabled without a secret, got %v", err)
+	}
+}
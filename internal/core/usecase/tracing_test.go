@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestReprocess_JobContinuesRequestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "error", SourceKey: "sources/video1.mp4"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error {
+		videoFile.Status = "ready_to_download"
+		return videoRepo.CompleteProcessing(ctx, entity.NewVideoArchive(*videoFile), entity.NewVideoEvent(*videoFile, entity.EventVideoReady))
+	}
+
+	ctx, request := provider.Tracer("test").Start(context.Background(), "POST /videos/{id}/reprocess")
+	_, err := videoUseCase.Reprocess(ctx, "video1", "123", 0)
+	request.End()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The request has already returned; the job outlives it.
+	drainCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	videoUseCase.Drain(drainCtx)
+
+	var job *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i := range spans {
+		if spans[i].Name == "process video" {
+			job = &spans[i]
+		}
+	}
+	if job == nil {
+		t.Fatalf("Expected a process video span, got %+v", spans.Snapshots())
+	}
+	if job.SpanContext.TraceID() != request.SpanContext().TraceID() || job.Parent.SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("Expected job span to be a child of the request span")
+	}
+}
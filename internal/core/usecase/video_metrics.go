@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+// Error classes reported to VideoMetrics.JobFailed.
+const (
+	ErrorClassTransient = "transient"
+	ErrorClassPermanent = "permanent"
+	ErrorClassExhausted = "exhausted"
+	ErrorClassCancelled = "cancelled"
+)
+
+const (
+	StorageUpload   = "upload"
+	StorageDownload = "download"
+)
+
+// nopMetrics is used until a real VideoMetrics is wired in.
+type nopMetrics struct{}
+
+func (nopMetrics) UploadReceived(sizeBytes int64)                          {}
+func (nopMetrics) JobSucceeded(frameCount int)                             {}
+func (nopMetrics) JobFailed(errorClass string)                             {}
+func (nopMetrics) ObserveFFmpeg(duration time.Duration)                    {}
+func (nopMetrics) ObserveZip(duration time.Duration)                       {}
+func (nopMetrics) ObserveStorage(operation string, duration time.Duration) {}
+
+func errorClass(ctx context.Context, err error) string {
+	switch {
+	case ctx.Err() != nil || errors.Is(err, entity.ErrStatusConflict):
+		return ErrorClassCancelled
+	case !entity.IsTransient(err):
+		return ErrorClassPermanent
+	default:
+		return ErrorClassTransient
+	}
+}
+
+// InFlightJobs returns how many jobs are running on this worker.
+func (v *VideoUseCase) InFlightJobs() int {
+	return v.runningJobs()
+}
+
+// QueueDepth returns how many videos are waiting for or undergoing
+// processing across all workers.
+func (v *VideoUseCase) QueueDepth() (int, error) {
+	return v.Repository.CountByStatus(context.Background(), "processing")
+}
@@ -2,108 +2,866 @@ package usecase
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/bits"
+	"math/rand/v2"
 	"mime/multipart"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
 	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/gomesmatheus/tc-hackaton/internal/core/usecase")
+
 const (
 	allowedFileExtensions = ".mp4"
 	allowedMimeTypes      = "video/mp4"
 	secondsInterval       = 4
+	maxSecondsInterval    = 3600
+
+	defaultMaxAttempts    = 3
+	defaultRetryBaseDelay = 2 * time.Second
+	defaultRetryMaxDelay  = time.Minute
+
+	defaultVideoPageSize = 50
+	maxVideoPageSize     = 200
 )
 
 type VideoUseCase struct {
 	Repository    port.VideoRepository
 	ZipRepository port.ZipRepository
+	Signaler      port.JobSignaler
+	Metrics       port.VideoMetrics
+	Logger        *slog.Logger
+	// Usage, when set, tracks what each owner consumes so Quota can be
+	// enforced on new uploads and reprocessing.
+	Usage port.UsageRepository
+	Quota entity.Quota
+	// Erasures, when set, is checked so owners can't add videos while
+	// their data is being erased.
+	Erasures port.ErasureRepository
+	// Retention is how long finished videos keep their archives before
+	// RunJanitor expires them.
+	Retention entity.RetentionPolicy
+	// Shares, when set, lets owners a video was shared with list and
+	// download its archives.
+	Shares port.ShareRepository
+	// RetainSource keeps the original upload in object storage so failed or
+	// finished videos can be reprocessed without uploading them again.
+	RetainSource bool
+	// MaxAttempts is the attempt budget for transient failures, after which
+	// the video is moved to dead_letter.
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// WorkerId identifies this process on the jobs it runs, so jobs left
+	// behind by dead workers can be detected through their heartbeats. It
+	// must not be reused by a restarted process; see NewWorkerId.
+	WorkerId string
+	// MaxJobsPerOwner caps how many videos one owner can have processing at
+	// once across all replicas; zero means no cap.
+	MaxJobsPerOwner int
+	// Per-operation timeouts applied on top of the job or request context;
+	// zero leaves the operation bounded only by its caller.
+	FFmpegTimeout   time.Duration
+	ZipTimeout      time.Duration
+	UploadTimeout   time.Duration
+	DownloadTimeout time.Duration
+
+	mu       sync.Mutex
+	jobs     map[string]context.CancelFunc
+	draining atomic.Bool
+	runJob   func(ctx context.Context, videoFile *entity.VideoFile) error
 }
 
 func NewVideoUseCase(repository port.VideoRepository, zipRepository port.ZipRepository) *VideoUseCase {
-	return &VideoUseCase{
-		Repository:    repository,
-		ZipRepository: zipRepository,
+	v := &VideoUseCase{
+		Repository:     repository,
+		ZipRepository:  zipRepository,
+		Metrics:        nopMetrics{},
+		Logger:         slog.Default(),
+		MaxAttempts:    defaultMaxAttempts,
+		RetryBaseDelay: defaultRetryBaseDelay,
+		RetryMaxDelay:  defaultRetryMaxDelay,
+		WorkerId:       NewWorkerId(""),
+		jobs:           make(map[string]context.CancelFunc),
 	}
+	v.runJob = v.run
+
+	return v
 }
 
-func (v *VideoUseCase) GenerateFrames(file multipart.File, header *multipart.FileHeader, ownerId string) error {
+// GenerateFrames stores the upload and starts extracting its frames in the
+// background, returning as soon as the video has been accepted.
+func (v *VideoUseCase) GenerateFrames(ctx context.Context, file multipart.File, header *multipart.FileHeader, ownerId string) (*entity.VideoFileResponse, error) {
+	if v.Draining() {
+		return nil, entity.ErrShuttingDown
+	}
+
+	err := v.checkErasure(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.admit(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := entity.Usage{OwnerId: ownerId, Videos: 1}
+	if v.RetainSource {
+		reserved.SourceBytes = header.Size
+	}
+	err = v.reserveUsage(ctx, reserved)
+	if err != nil {
+		return nil, err
+	}
+
 	videoFile, err := entity.NewVideoFile(file, header, ownerId)
 	if err != nil {
-		return err
+		v.releaseUsage(ctx, reserved)
+		return nil, err
+	}
+	ctx = logging.With(ctx, slog.String(logging.VideoID, videoFile.Id))
+	v.Logger.InfoContext(ctx, "Video uploaded", "name", header.Filename, "size", header.Size)
+	videoFile.IntervalSeconds = secondsInterval
+	v.Metrics.UploadReceived(header.Size)
+
+	if v.RetainSource {
+		err = v.uploadSource(ctx, videoFile)
+		if err != nil {
+			videoFile.Delete()
+			v.releaseUsage(ctx, reserved)
+			return nil, err
+		}
+	}
+
+	// Storing the source can take a while; an erasure requested meanwhile
+	// must not miss this video.
+	err = v.checkErasure(ctx, ownerId)
+	if err == nil {
+		err = v.Repository.Save(ctx, *videoFile, v.WorkerId)
+	}
+	if err != nil {
+		if videoFile.SourceKey != "" {
+			v.ZipRepository.Delete(context.WithoutCancel(ctx), videoFile.SourceKey)
+		}
+		videoFile.Delete()
+		v.releaseUsage(ctx, reserved)
+		return nil, err
+	}
+	if videoFile.SourceSize != reserved.SourceBytes {
+		// The reservation went by the declared size; settle it with the
+		// size actually stored.
+		v.recordUsage(ctx, entity.Usage{OwnerId: ownerId, SourceBytes: videoFile.SourceSize - reserved.SourceBytes})
+	}
+
+	response := GetVideosResponse([]entity.VideoFile{*videoFile})[0]
+	jobCtx := v.startJob(ctx, videoFile.Id)
+	go v.process(jobCtx, videoFile)
+
+	return &response, nil
+}
+
+// process runs the job, retrying transient failures with exponential backoff
+// until it succeeds, fails permanently or exhausts its attempt budget.
+func (v *VideoUseCase) process(ctx context.Context, videoFile *entity.VideoFile) (err error) {
+	defer v.finishJob(videoFile.Id)
+
+	ctx, span := tracer.Start(ctx, "process video", trace.WithAttributes(
+		attribute.String("video.id", videoFile.Id),
+		attribute.Int("video.version", videoFile.Version),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("video.attempts", videoFile.Attempts))
+		recordError(span, err)
+		span.End()
+	}()
+
+	ctx = logging.With(ctx,
+		slog.String(logging.VideoID, videoFile.Id),
+		slog.String(logging.OwnerID, videoFile.OwnerId),
+		slog.String(logging.WorkerID, v.WorkerId),
+	)
+	err = v.Repository.ClaimJob(ctx, *videoFile, v.WorkerId)
+	if err != nil {
+		v.Logger.ErrorContext(ctx, "Error claiming job", "error", err)
+	}
+
+	// Requeued jobs keep counting from where the previous worker stopped.
+	lastError := videoFile.LastError
+	for attempt := videoFile.Attempts + 1; ; attempt++ {
+		ctx := logging.With(ctx, slog.Int(logging.Attempt, attempt))
+		videoFile.Attempts = attempt
+		v.Repository.RecordAttempt(ctx, videoFile.Id, attempt, lastError)
+
+		v.Logger.InfoContext(ctx, "Processing video", "version", videoFile.Version, "interval_seconds", videoFile.IntervalSeconds)
+		start := time.Now()
+		err := v.runJob(ctx, videoFile)
+		// Failed attempts used the CPU too.
+		v.recordUsage(context.WithoutCancel(ctx), entity.Usage{OwnerId: videoFile.OwnerId, ProcessingSeconds: time.Since(start).Seconds()})
+		if err == nil {
+			v.Metrics.JobSucceeded(videoFile.FrameCount)
+			v.Logger.InfoContext(ctx, "Video processed", "frames", videoFile.FrameCount)
+			go cleanup(*videoFile)
+			return nil
+		}
+
+		class := errorClass(ctx, err)
+		v.Logger.ErrorContext(ctx, "Error processing video", "error", err, "error_class", class)
+		if class == ErrorClassCancelled {
+			// Cancel already changed the status; just discard what was produced.
+			v.Metrics.JobFailed(class)
+			v.ZipRepository.Delete(context.WithoutCancel(ctx), videoFile.GetZipFileName())
+			go cleanup(*videoFile)
+			return err
+		}
+
+		videoFile.LastError = err.Error()
+		lastError = videoFile.LastError
+		if class == ErrorClassPermanent {
+			v.Metrics.JobFailed(class)
+			return v.fail(ctx, videoFile, entity.EventVideoFailed, "error", err)
+		}
+		if attempt >= v.MaxAttempts {
+			v.Metrics.JobFailed(ErrorClassExhausted)
+			return v.fail(ctx, videoFile, entity.EventVideoDeadLettered, "dead_letter", err)
+		}
+		v.Metrics.JobFailed(class)
+
+		cleanupScratch(*videoFile)
+		select {
+		case <-ctx.Done():
+			go cleanup(*videoFile)
+			return ctx.Err()
+		case <-time.After(backoff(attempt, v.RetryBaseDelay, v.RetryMaxDelay)):
+		}
 	}
+}
 
-	v.Repository.Save(*videoFile)
+// run executes a single attempt of the pipeline.
+func (v *VideoUseCase) run(ctx context.Context, videoFile *entity.VideoFile) error {
+	err := v.restoreSource(ctx, videoFile)
+	if err != nil {
+		return err
+	}
 
-	err = GenerateVideoFrames(videoFile.GetFileName(), secondsInterval)
+	start := time.Now()
+	ffmpegCtx, cancel := withTimeout(ctx, v.FFmpegTimeout)
+	err = GenerateVideoFrames(ffmpegCtx, videoFile.GetFileName(), videoFile.GetFramesOutput(), videoFile.IntervalSeconds)
+	cancel()
 	if err != nil {
-		videoFile.ErrorProcessing()
-		v.Repository.UpdateStatus(videoFile.Id, "error")
-		fmt.Println("Error generating frames", err)
 		return err
 	}
+	v.Metrics.ObserveFFmpeg(time.Since(start))
 
-	zipFilePath, err := ZipFrames(videoFile.Id, "frame_*.png")
+	videoFile.FrameCount = CountFrames(videoFile.GetFramesPattern())
+	start = time.Now()
+	zipCtx, cancel := withTimeout(ctx, v.ZipTimeout)
+	zipFilePath, err := ZipFrames(zipCtx, videoFile.GetOutputZipPath(), videoFile.GetFramesPattern())
+	cancel()
 	if err != nil {
-		videoFile.ErrorProcessing()
-		v.Repository.UpdateStatus(videoFile.Id, "error")
-		fmt.Println("Error zipping frames", err)
 		return err
 	}
+	v.Metrics.ObserveZip(time.Since(start))
 
 	zipFile, err := os.Open(zipFilePath)
 	if err != nil {
-		fmt.Println("Error opening file:", err)
 		return err
 	}
 	defer zipFile.Close()
 
-	err = v.ZipRepository.UploadFile(videoFile.GetZipFileName(), zipFile)
+	archive := entity.NewVideoArchive(*videoFile)
+	if info, err := zipFile.Stat(); err == nil {
+		archive.Size = info.Size()
+	}
+
+	err = v.uploadFile(ctx, videoFile.GetZipFileName(), zipFile)
 	if err != nil {
-		go videoFile.ErrorProcessing()
-		go v.Repository.UpdateStatus(videoFile.Id, "error")
-		go DeleteFrames("frame_*.png")
-		go os.Remove(fmt.Sprintf("output_%s", videoFile.GetZipFileName()))
-		fmt.Println("Error uploading zip file", err)
 		return err
 	}
 
-	go v.Repository.UpdateStatus(videoFile.Id, "ready_to_download")
-	go DeleteFrames("frame_*.png")
-	go videoFile.Delete()
-	go os.Remove(fmt.Sprintf("output_%s", videoFile.GetZipFileName()))
+	videoFile.Status = "ready_to_download"
+	err = v.Repository.CompleteProcessing(ctx, archive, entity.NewVideoEvent(*videoFile, entity.EventVideoReady))
+	if err != nil {
+		return err
+	}
+	v.recordUsage(ctx, entity.Usage{OwnerId: videoFile.OwnerId, ArchiveBytes: archive.Size})
 
 	return nil
 }
 
-func (v *VideoUseCase) GetVideos(ownerId string) ([]entity.VideoFileResponse, error) {
-	videos, err := v.Repository.FindByOwnerId(ownerId)
+func (v *VideoUseCase) fail(ctx context.Context, videoFile *entity.VideoFile, event string, status string, err error) error {
+	v.Repository.RecordAttempt(ctx, videoFile.Id, videoFile.Attempts, videoFile.LastError)
+	v.transition(ctx, *videoFile, event, status)
+	videoFile.ErrorProcessing()
+	go cleanup(*videoFile)
+
+	return err
+}
+
+// Cancel marks a processing video as cancelled and signals whichever replica
+// is running it to stop.
+func (v *VideoUseCase) Cancel(ctx context.Context, videoId string, ownerId string) (*entity.VideoFileResponse, error) {
+	video, err := v.Repository.FindById(ctx, videoId)
+	if err != nil || video.OwnerId != ownerId {
+		return nil, entity.ErrVideoNotFound
+	}
+
+	video.Status = "cancelled"
+	err = v.Repository.TransitionStatus(ctx, entity.NewVideoEvent(*video, entity.EventVideoCancelled), "processing")
+	if err != nil {
+		return nil, err
+	}
+
+	v.Abort(videoId)
+	if v.Signaler != nil {
+		err = v.Signaler.SignalCancel(videoId)
+		if err != nil {
+			v.Logger.ErrorContext(ctx, "Error signaling cancellation", logging.VideoID, videoId, "error", err)
+		}
+	}
+
+	response := GetVideosResponse([]entity.VideoFile{*video})[0]
+	return &response, nil
+}
+
+// Reprocess re-runs frame extraction from the retained source, optionally with
+// a new interval, producing a new archive version.
+func (v *VideoUseCase) Reprocess(ctx context.Context, videoId string, ownerId string, intervalSeconds int) (*entity.VideoFileResponse, error) {
+	if v.Draining() {
+		return nil, entity.ErrShuttingDown
+	}
+
+	if intervalSeconds < 0 || intervalSeconds > maxSecondsInterval {
+		return nil, entity.ErrInvalidParameters
+	}
+	if intervalSeconds == 0 {
+		intervalSeconds = secondsInterval
+	}
+
+	video, err := v.Repository.FindById(ctx, videoId)
+	if err != nil || video.OwnerId != ownerId {
+		return nil, entity.ErrVideoNotFound
+	}
+
+	if video.Status == "processing" {
+		return nil, entity.ErrStatusConflict
+	}
+
+	if video.Status == "expired" {
+		return nil, entity.ErrVideoExpired
+	}
+
+	if video.SourceKey == "" {
+		return nil, entity.ErrSourceUnavailable
+	}
+
+	err = v.checkErasure(ctx, video.OwnerId)
+	if err != nil {
+		return nil, err
+	}
+	err = v.admit(ctx, video.OwnerId)
+	if err != nil {
+		return nil, err
+	}
+	err = v.checkQuota(ctx, video.OwnerId, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	archives, err := v.Repository.FindArchives(ctx, videoId)
 	if err != nil {
 		return nil, err
 	}
 
-	return GetVideosResponse(videos), nil
+	from := video.Status
+	video.Version = nextVersion(*video, archives)
+	video.IntervalSeconds = intervalSeconds
+	video.FrameCount = 0
+	video.Attempts = 0
+	video.LastError = ""
+	video.Status = "processing"
+	err = v.Repository.TransitionStatus(ctx, entity.NewVideoEvent(*video, entity.EventVideoReprocessing), from)
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetVideosResponse([]entity.VideoFile{*video})[0]
+	jobCtx := v.startJob(ctx, video.Id)
+	go v.process(jobCtx, video)
+
+	return &response, nil
 }
 
-func (v *VideoUseCase) DownloadZip(videoId string, ownerId string) (io.Reader, error) {
-	video, err := v.Repository.FindById(videoId)
+func (v *VideoUseCase) GetArchives(ctx context.Context, videoId string, ownerId string) ([]entity.VideoArchiveResponse, error) {
+	video, err := v.Repository.FindById(ctx, videoId)
+	if err != nil {
+		return nil, entity.ErrVideoNotFound
+	}
+
+	err = v.authorize(ctx, *video, ownerId, entity.ScopeRead)
 	if err != nil {
 		return nil, err
 	}
 
+	archives, err := v.Repository.FindArchives(ctx, videoId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entity.VideoArchiveResponse, 0)
+	for _, archive := range archives {
+		response = append(response, entity.VideoArchiveResponse{
+			Version:         archive.Version,
+			FrameCount:      archive.FrameCount,
+			IntervalSeconds: archive.IntervalSeconds,
+			CreatedAt:       archive.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (v *VideoUseCase) uploadSource(ctx context.Context, videoFile *entity.VideoFile) error {
+	source, err := os.Open(videoFile.GetFileName())
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	err = v.uploadFile(ctx, videoFile.GetSourceKey(), source)
+	if err != nil {
+		v.Logger.ErrorContext(ctx, "Error uploading source video", "error", err)
+		return err
+	}
+
+	videoFile.SourceKey = videoFile.GetSourceKey()
+	if info, err := source.Stat(); err == nil {
+		videoFile.SourceSize = info.Size()
+	}
+	return nil
+}
+
+// restoreSource downloads the retained upload into the working directory
+// unless it is still there from a previous run.
+func (v *VideoUseCase) restoreSource(ctx context.Context, videoFile *entity.VideoFile) error {
+	if _, err := os.Stat(videoFile.GetFileName()); err == nil {
+		return nil
+	}
+	if videoFile.SourceKey == "" {
+		return entity.Permanent(entity.ErrSourceUnavailable)
+	}
+
+	source, err := v.downloadFile(ctx, videoFile.SourceKey)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(videoFile.GetFileName())
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, source)
+	return err
+}
+
+func nextVersion(video entity.VideoFile, archives []entity.VideoArchive) int {
+	version := 0
+	if video.Status == "ready_to_download" {
+		// Videos processed before archives were versioned have a single
+		// archive under the original key.
+		version = 1
+	}
+
+	for _, archive := range archives {
+		version = max(version, archive.Version)
+	}
+
+	return version + 1
+}
+
+// Abort stops the job for the video if it is running on this replica.
+func (v *VideoUseCase) Abort(videoId string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if cancel, exists := v.jobs[videoId]; exists {
+		cancel()
+	}
+}
+
+func (v *VideoUseCase) Delete(ctx context.Context, videoId string, ownerId string) error {
+	video, err := v.Repository.FindById(ctx, videoId)
+	if errors.Is(err, entity.ErrVideoNotFound) {
+		// Already gone, e.g. a retried request.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 	if video.OwnerId != ownerId {
-		return nil, fmt.Errorf("Video not found")
+		return entity.ErrVideoNotFound
 	}
 
-	if video.Status != "ready_to_download" {
-		return nil, fmt.Errorf("Video not ready to download")
+	v.Logger.InfoContext(ctx, "Deleting video", logging.VideoID, video.Id, "status", video.Status)
+	return v.purge(ctx, *video)
+}
+
+// purge stops the video's job and deletes its archives, retained source and
+// record. Storage is cleaned up first so a failure leaves the record behind
+// for the purge to be retried.
+func (v *VideoUseCase) purge(ctx context.Context, video entity.VideoFile) error {
+	if video.Status == "processing" {
+		// Cancelling first makes a job that is about to complete fail on the
+		// status conflict and discard its archive, instead of recording one
+		// after its objects were deleted.
+		cancelled := video
+		cancelled.Status = "cancelled"
+		err := v.Repository.TransitionStatus(ctx, entity.NewVideoEvent(cancelled, entity.EventVideoCancelled), "processing")
+		if errors.Is(err, entity.ErrStatusConflict) {
+			// The job finished in the meantime; purge what it left.
+			current, err := v.Repository.FindById(ctx, video.Id)
+			if errors.Is(err, entity.ErrVideoNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return v.purge(ctx, *current)
+		}
+		if err != nil {
+			return err
+		}
+		video = cancelled
+
+		v.Abort(video.Id)
+		if v.Signaler != nil {
+			err := v.Signaler.SignalCancel(video.Id)
+			if err != nil {
+				v.Logger.ErrorContext(ctx, "Error signaling cancellation", logging.VideoID, video.Id, "error", err)
+			}
+		}
+	}
+
+	archiveBytes, err := v.deleteObjects(ctx, video)
+	if err != nil {
+		return err
 	}
 
-	file, err := v.ZipRepository.DownloadFile(video.GetZipFileName())
+	err = v.Repository.Delete(ctx, video.Id)
+	if err != nil {
+		return err
+	}
+
+	delta := entity.Usage{OwnerId: video.OwnerId, Videos: -1}
+	if video.Status != "expired" {
+		// Expiring already released the storage.
+		delta.SourceBytes = -video.SourceSize
+		delta.ArchiveBytes = -archiveBytes
+	}
+	v.recordUsage(ctx, delta)
+
+	return nil
+}
+
+// deleteObjects deletes the video's archives and retained source from
+// storage, returning the archives' total size.
+func (v *VideoUseCase) deleteObjects(ctx context.Context, video entity.VideoFile) (int64, error) {
+	archives, err := v.Repository.FindArchives(ctx, video.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := []string{}
+	archiveBytes := int64(0)
+	for _, archive := range archives {
+		keys = append(keys, archive.Key)
+		archiveBytes += archive.Size
+	}
+	if len(archives) == 0 {
+		// Videos processed before archives were versioned only have the
+		// original <id>.zip key; deleting a missing key is not an error.
+		keys = append(keys, entity.GetArchiveKey(video.Id, 1))
+	}
+	if video.SourceKey != "" {
+		keys = append(keys, video.SourceKey)
+	}
+
+	for _, key := range keys {
+		err = v.ZipRepository.Delete(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return archiveBytes, nil
+}
+
+func (v *VideoUseCase) GetUsage(ctx context.Context, ownerId string) (*entity.UsageResponse, error) {
+	usage := &entity.Usage{OwnerId: ownerId}
+	if v.Usage != nil {
+		var err error
+		usage, err = v.Usage.Get(ctx, ownerId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := entity.UsageResponse{
+		Videos:            usage.Videos,
+		SourceBytes:       usage.SourceBytes,
+		ArchiveBytes:      usage.ArchiveBytes,
+		StorageBytes:      usage.StorageBytes(),
+		ProcessingSeconds: usage.ProcessingSeconds,
+		Limits: entity.QuotaResponse{
+			MaxVideos:            v.Quota.MaxVideos,
+			MaxStorageBytes:      v.Quota.MaxStorageBytes,
+			MaxProcessingSeconds: v.Quota.MaxProcessingSeconds,
+		},
+	}
+	if !usage.UpdatedAt.IsZero() {
+		response.UpdatedAt = &usage.UpdatedAt
+	}
+
+	return &response, nil
+}
+
+// checkErasure refuses new work from owners whose data is being erased, so
+// nothing is left behind once the erasure completes.
+func (v *VideoUseCase) checkErasure(ctx context.Context, ownerId string) error {
+	if v.Erasures == nil {
+		return nil
+	}
+
+	_, err := v.Erasures.FindActive(ctx, ownerId)
+	if errors.Is(err, entity.ErrErasureNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return entity.ErrErasureInProgress
+}
+
+// checkQuota refuses work that would take the owner over Quota, given the
+// videos and bytes it is about to add.
+func (v *VideoUseCase) checkQuota(ctx context.Context, ownerId string, videos int, bytes int64) error {
+	if v.Usage == nil {
+		return nil
+	}
+
+	usage, err := v.Usage.Get(ctx, ownerId)
+	if err != nil {
+		return err
+	}
+
+	return v.Quota.Check(*usage, videos, bytes)
+}
+
+// reserveUsage takes delta out of the owner's quota up front, so uploads
+// accepted concurrently can't exceed it together.
+func (v *VideoUseCase) reserveUsage(ctx context.Context, delta entity.Usage) error {
+	if v.Usage == nil {
+		return nil
+	}
+
+	return v.Usage.Reserve(ctx, delta, v.Quota)
+}
+
+// releaseUsage gives back a reservation for work that was not accepted.
+func (v *VideoUseCase) releaseUsage(ctx context.Context, delta entity.Usage) {
+	v.recordUsage(context.WithoutCancel(ctx), entity.Usage{
+		OwnerId:           delta.OwnerId,
+		Videos:            -delta.Videos,
+		SourceBytes:       -delta.SourceBytes,
+		ArchiveBytes:      -delta.ArchiveBytes,
+		ProcessingSeconds: -delta.ProcessingSeconds,
+	})
+}
+
+// recordUsage only logs failures: losing an update skews the numbers a
+// little, which is better than failing the work that was already done.
+func (v *VideoUseCase) recordUsage(ctx context.Context, delta entity.Usage) {
+	if v.Usage == nil {
+		return
+	}
+
+	err := v.Usage.Add(ctx, delta)
+	if err != nil {
+		v.Logger.ErrorContext(ctx, "Error recording usage", logging.OwnerID, delta.OwnerId, "error", err)
+	}
+}
+
+// Drain stops accepting new jobs and waits for the running ones to finish.
+// Jobs still running when ctx expires are stopped and released so another
+// replica's reconciler requeues them.
+func (v *VideoUseCase) Drain(ctx context.Context) error {
+	v.draining.Store(true)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for v.runningJobs() > 0 {
+		select {
+		case <-ctx.Done():
+			v.Logger.WarnContext(ctx, "Drain deadline reached, releasing jobs", "jobs", v.runningJobs())
+			v.mu.Lock()
+			for _, cancel := range v.jobs {
+				cancel()
+			}
+			v.mu.Unlock()
+
+			return v.Repository.ReleaseJobs(context.WithoutCancel(ctx), v.WorkerId)
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func (v *VideoUseCase) Draining() bool {
+	return v.draining.Load()
+}
+
+func (v *VideoUseCase) runningJobs() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return len(v.jobs)
+}
+
+// startJob registers a cancellable context for the job. It keeps the values
+// of parent, so the job's spans continue the trace of the request that
+// started it, but not its cancellation.
+func (v *VideoUseCase) startJob(parent context.Context, videoId string) context.Context {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+
+	v.mu.Lock()
+	v.jobs[videoId] = cancel
+	v.mu.Unlock()
+
+	return ctx
+}
+
+func (v *VideoUseCase) finishJob(videoId string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if cancel, exists := v.jobs[videoId]; exists {
+		cancel()
+		delete(v.jobs, videoId)
+	}
+}
+
+// transition persists the status change together with its outbox event, so
+// notifications are only ever published for changes that were committed.
+func (v *VideoUseCase) transition(ctx context.Context, videoFile entity.VideoFile, event string, status string) error {
+	videoFile.Status = status
+	err := v.Repository.TransitionStatus(ctx, entity.NewVideoEvent(videoFile, event), "processing")
+	if err != nil {
+		v.Logger.ErrorContext(ctx, "Error updating video status", "status", status, "error", err)
+	}
+
+	return err
+}
+
+// GetVideos lists one page of the owner's videos matching filter.
+func (v *VideoUseCase) GetVideos(ctx context.Context, ownerId string, filter entity.VideoFilter) (*entity.VideoPage, error) {
+	filter.OwnerId = ownerId
+	if filter.Sort == "" {
+		filter.Sort = entity.SortCreatedAt
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultVideoPageSize
+	}
+	if limit > maxVideoPageSize {
+		limit = maxVideoPageSize
+	}
+	// One extra video tells whether there is a next page.
+	filter.Limit = limit + 1
+
+	videos, err := v.Repository.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := v.Repository.CountAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.VideoPage{Total: total}
+	if len(videos) > limit {
+		videos = videos[:limit]
+		page.NextCursor = entity.NewVideoCursor(videos[limit-1], filter).Encode()
+	}
+	page.Videos = GetVideosResponse(videos)
+
+	return page, nil
+}
+
+// DownloadZip returns the requested archive version, or the latest one when
+// version is zero. Earlier versions stay downloadable while a video is being
+// reprocessed.
+func (v *VideoUseCase) DownloadZip(ctx context.Context, videoId string, ownerId string, version int) (io.Reader, error) {
+	video, err := v.Repository.FindById(ctx, videoId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.authorize(ctx, *video, ownerId, entity.ScopeDownload)
+	if err != nil {
+		return nil, err
+	}
+
+	if video.Status == "expired" {
+		return nil, entity.ErrVideoExpired
+	}
+
+	archives, err := v.Repository.FindArchives(ctx, videoId)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ""
+	latest := 0
+	for _, archive := range archives {
+		if archive.Version == version || (version == 0 && archive.Version > latest) {
+			key = archive.Key
+			latest = archive.Version
+		}
+	}
+
+	if key == "" && len(archives) == 0 && video.Status == "ready_to_download" && version <= 1 {
+		key = entity.GetArchiveKey(video.Id, 1)
+	}
+
+	if key == "" {
+		return nil, entity.ErrVideoNotReady
+	}
+
+	file, err := v.downloadFile(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -111,44 +869,158 @@ func (v *VideoUseCase) DownloadZip(videoId string, ownerId string) (io.Reader, e
 	return file, nil
 }
 
-func GenerateVideoFrames(videoFilePath string, seconds int) error {
-	cmd := exec.Command("ffmpeg", "-i", videoFilePath, "-vf", fmt.Sprintf("fps=1/%d", seconds), "frame_%04d.png")
+// admit enforces MaxJobsPerOwner on new jobs. The count is read without a
+// lock, so simultaneous requests on different replicas can overshoot the cap
+// by a job or two; the rate limiter keeps that window small.
+func (v *VideoUseCase) admit(ctx context.Context, ownerId string) error {
+	if v.MaxJobsPerOwner <= 0 {
+		return nil
+	}
+
+	processing, err := v.Repository.FindAll(ctx, entity.VideoFilter{OwnerId: ownerId, Status: "processing", Limit: v.MaxJobsPerOwner})
+	if err != nil {
+		return err
+	}
+	if len(processing) >= v.MaxJobsPerOwner {
+		return entity.ErrTooManyJobs
+	}
+
+	return nil
+}
+
+// authorize lets the owner through, and anyone else holding an active grant
+// that covers permission. Everyone else is told the video doesn't exist.
+func (v *VideoUseCase) authorize(ctx context.Context, video entity.VideoFile, ownerId string, permission string) error {
+	if video.OwnerId == ownerId {
+		return nil
+	}
+	if v.Shares == nil {
+		return entity.ErrVideoNotFound
+	}
+
+	grant, err := v.Shares.FindGrant(ctx, video.Id, ownerId)
+	if errors.Is(err, entity.ErrShareNotFound) {
+		return entity.ErrVideoNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !grant.Allows(permission) {
+		return entity.ErrVideoNotFound
+	}
+
+	return nil
+}
+
+func (v *VideoUseCase) uploadFile(ctx context.Context, key string, file io.Reader) error {
+	ctx, cancel := withTimeout(ctx, v.UploadTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := v.ZipRepository.UploadFile(ctx, key, file)
+	if err == nil {
+		v.Metrics.ObserveStorage(StorageUpload, time.Since(start))
+	}
+
+	return err
+}
+
+// downloadFile bounds the download only; the returned reader is already
+// buffered in memory, so it stays readable after the timeout is released.
+func (v *VideoUseCase) downloadFile(ctx context.Context, key string) (io.Reader, error) {
+	ctx, cancel := withTimeout(ctx, v.DownloadTimeout)
+	defer cancel()
+
+	start := time.Now()
+	file, err := v.ZipRepository.DownloadFile(ctx, key)
+	if err == nil {
+		v.Metrics.ObserveStorage(StorageDownload, time.Since(start))
+	}
+
+	return file, err
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+func GenerateVideoFrames(ctx context.Context, videoFilePath string, framesOutput string, seconds int) (err error) {
+	ctx, span := tracer.Start(ctx, "ffmpeg", trace.WithAttributes(attribute.Int("ffmpeg.interval_seconds", seconds)))
+	defer func() {
+		recordError(span, err)
+		span.End()
+	}()
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", videoFilePath, "-vf", fmt.Sprintf("fps=1/%d", seconds), framesOutput)
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
-	if err == nil {
+	err = cmd.Run()
+	if err != nil {
+		slog.ErrorContext(ctx, "Error generating frames", "error", err, "stderr", stderr.String())
+		var exitErr *exec.ExitError
+		if ctx.Err() == nil && errors.As(err, &exitErr) {
+			// ffmpeg rejected the input, retrying won't help.
+			return entity.Permanent(err)
+		}
+		if ctx.Err() != nil {
+			// Report why ffmpeg was killed rather than the bare signal, so a
+			// timeout is retried and a cancellation is not.
+			return fmt.Errorf("ffmpeg: %w", ctx.Err())
+		}
 		return err
 	}
 
 	return nil
 }
 
-func ZipFrames(id string, filesPattern string) (string, error) {
+func ZipFrames(ctx context.Context, zipFilePath string, filesPattern string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "zip")
+	defer func() {
+		recordError(span, err)
+		span.End()
+	}()
+
 	files, err := filepath.Glob(filesPattern)
-	zipFilePath := fmt.Sprintf("output_%s.zip", id)
 	if err != nil {
-		fmt.Println("error getting files", err)
+		slog.ErrorContext(ctx, "Error listing frames", "error", err)
 		return "", err
 	}
 
+	span.SetAttributes(attribute.Int("zip.files", len(files)))
 	args := append([]string{zipFilePath}, files...)
-	cmd := exec.Command("zip", args...)
-	fmt.Println("zipping files", cmd)
+	cmd := exec.CommandContext(ctx, "zip", args...)
+	slog.DebugContext(ctx, "Zipping frames", "files", len(files))
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
 	err = cmd.Run()
 	if err != nil {
-		fmt.Println("error zipping files", err, stderr.String())
+		slog.ErrorContext(ctx, "Error zipping frames", "error", err, "stderr", stderr.String())
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("zip: %w", ctx.Err())
+		}
 		return "", err
 	}
 
 	return zipFilePath, nil
 }
 
+func CountFrames(filesPattern string) int {
+	files, err := filepath.Glob(filesPattern)
+	if err != nil {
+		return 0
+	}
+
+	return len(files)
+}
+
 func DeleteFrames(filesPattern string) error {
 	files, err := filepath.Glob(filesPattern)
 	if err != nil {
@@ -158,7 +1030,7 @@ func DeleteFrames(filesPattern string) error {
 	for _, file := range files {
 		err := os.Remove(file)
 		if err != nil {
-			fmt.Println("error deleting file", file, err)
+			slog.Error("Error deleting file", "file", file, "error", err)
 			return err
 		}
 	}
@@ -166,15 +1038,53 @@ func DeleteFrames(filesPattern string) error {
 	return nil
 }
 
+func cleanup(videoFile entity.VideoFile) {
+	cleanupScratch(videoFile)
+	os.Remove(videoFile.GetFileName())
+}
+
+// cleanupScratch removes the frames and archive of an attempt, keeping the
+// source so the job can be retried.
+func cleanupScratch(videoFile entity.VideoFile) {
+	DeleteFrames(videoFile.GetFramesPattern())
+	os.Remove(videoFile.GetOutputZipPath())
+}
+
+// backoff returns an exponential delay with full jitter for the given attempt.
+func backoff(attempt int, base time.Duration, maxDelay time.Duration) time.Duration {
+	delay := maxDelay
+	// Only shift while the result fits without reaching the sign bit.
+	shift := attempt - 1
+	if base > 0 && shift >= 0 && shift < bits.LeadingZeros64(uint64(base))-1 && base<<shift < maxDelay {
+		delay = base << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
+
 func GetVideosResponse(videos []entity.VideoFile) []entity.VideoFileResponse {
 	response := make([]entity.VideoFileResponse, 0)
 	for _, video := range videos {
 		response = append(response, entity.VideoFileResponse{
-			OwnerId: video.OwnerId,
-			Id:      video.Id,
-			Status:  video.Status,
+			OwnerId:    video.OwnerId,
+			Id:         video.Id,
+			Status:     video.Status,
+			Attempts:   video.Attempts,
+			LastError:  video.LastError,
+			CreatedAt:  video.CreatedAt,
+			FinishedAt: video.FinishedAt,
 		})
 	}
 
 	return response
 }
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
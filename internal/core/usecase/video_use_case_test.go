@@ -2,24 +2,45 @@ package usecase
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
 )
 
 type MockVideoRepository struct {
-	videos []entity.VideoFile
+	mu       sync.Mutex
+	videos   []entity.VideoFile
+	events   []entity.VideoEvent
+	archives []entity.VideoArchive
+	stale    []entity.VideoFile
+	released []string
+	// finishedAt is when each video last produced an archive, for FindExpired.
+	finishedAt map[string]time.Time
 }
 
-func (r *MockVideoRepository) Save(video entity.VideoFile) error {
+func (r *MockVideoRepository) Save(ctx context.Context, video entity.VideoFile, workerId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.videos = append(r.videos, video)
 	return nil
 }
 
-func (r *MockVideoRepository) UpdateStatus(videoId, status string) error {
+func (r *MockVideoRepository) UpdateStatus(ctx context.Context, videoId, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateStatus(videoId, status)
+}
+
+func (r *MockVideoRepository) updateStatus(videoId, status string) error {
 	for i, v := range r.videos {
 		if v.Id == videoId {
 			r.videos[i].Status = status
@@ -29,7 +50,106 @@ func (r *MockVideoRepository) UpdateStatus(videoId, status string) error {
 	return fmt.Errorf("video not found")
 }
 
-func (r *MockVideoRepository) FindByOwnerId(ownerId string) ([]entity.VideoFile, error) {
+func (r *MockVideoRepository) TransitionStatus(ctx context.Context, event entity.VideoEvent, from string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	video, err := r.find(event.VideoId)
+	if err != nil {
+		return err
+	}
+	if video.Status != from {
+		return entity.ErrStatusConflict
+	}
+	r.events = append(r.events, event)
+	return r.updateStatus(event.VideoId, event.Status)
+}
+
+func (r *MockVideoRepository) CompleteProcessing(ctx context.Context, archive entity.VideoArchive, event entity.VideoEvent) error {
+	err := r.TransitionStatus(ctx, event, "processing")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.archives = append(r.archives, archive)
+	return nil
+}
+
+func (r *MockVideoRepository) RecordAttempt(ctx context.Context, videoId string, attempts int, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range r.videos {
+		if v.Id == videoId {
+			r.videos[i].Attempts = attempts
+			r.videos[i].LastError = lastError
+			return nil
+		}
+	}
+	return fmt.Errorf("video not found")
+}
+
+func (r *MockVideoRepository) ClaimJob(ctx context.Context, video entity.VideoFile, workerId string) error {
+	return nil
+}
+
+func (r *MockVideoRepository) Heartbeat(ctx context.Context, workerId string) error {
+	return nil
+}
+
+func (r *MockVideoRepository) CountByStatus(ctx context.Context, status string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, video := range r.videos {
+		if video.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MockVideoRepository) ReleaseJobs(ctx context.Context, workerId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.released = append(r.released, workerId)
+	return nil
+}
+
+func (r *MockVideoRepository) ClaimStaleJobs(ctx context.Context, workerId string, staleAfter time.Duration, limit int) ([]entity.VideoFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stale := r.stale
+	r.stale = nil
+	return stale, nil
+}
+
+func (r *MockVideoRepository) FindArchives(ctx context.Context, videoId string) ([]entity.VideoArchive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []entity.VideoArchive
+	for _, a := range r.archives {
+		if a.VideoId == videoId {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (r *MockVideoRepository) FindEvents(ctx context.Context, ownerId string) ([]entity.VideoEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []entity.VideoEvent
+	for _, e := range r.events {
+		if e.OwnerId == ownerId {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (r *MockVideoRepository) FindByOwnerId(ctx context.Context, ownerId string) ([]entity.VideoFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	var result []entity.VideoFile
 	for _, v := range r.videos {
 		if v.OwnerId == ownerId {
@@ -39,20 +159,121 @@ func (r *MockVideoRepository) FindByOwnerId(ownerId string) ([]entity.VideoFile,
 	return result, nil
 }
 
-func (r *MockVideoRepository) FindById(videoId string) (*entity.VideoFile, error) {
+func (r *MockVideoRepository) FindAll(ctx context.Context, filter entity.VideoFilter) ([]entity.VideoFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []entity.VideoFile
+	for _, v := range r.matching(filter) {
+		if filter.After != nil && !after(v, filter) {
+			continue
+		}
+		result = append(result, v)
+	}
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (r *MockVideoRepository) CountAll(ctx context.Context, filter entity.VideoFilter) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.matching(filter)), nil
+}
+
+// matching returns the videos matching filter, in its sort order.
+func (r *MockVideoRepository) matching(filter entity.VideoFilter) []entity.VideoFile {
+	var result []entity.VideoFile
+	for _, v := range r.videos {
+		if (filter.OwnerId != "" && v.OwnerId != filter.OwnerId) || (filter.Status != "" && v.Status != filter.Status) {
+			continue
+		}
+		if (!filter.CreatedAfter.IsZero() && v.CreatedAt.Before(filter.CreatedAfter)) || (!filter.CreatedBefore.IsZero() && !v.CreatedAt.Before(filter.CreatedBefore)) {
+			continue
+		}
+		result = append(result, v)
+	}
+	slices.SortFunc(result, func(a, b entity.VideoFile) int {
+		c := a.SortKey(filter.Sort).Compare(b.SortKey(filter.Sort))
+		if c == 0 {
+			c = strings.Compare(a.Id, b.Id)
+		}
+		if filter.Descending {
+			return -c
+		}
+		return c
+	})
+	return result
+}
+
+// after reports whether v comes after filter's cursor.
+func after(v entity.VideoFile, filter entity.VideoFilter) bool {
+	c := v.SortKey(filter.Sort).Compare(filter.After.Key)
+	if c == 0 {
+		c = strings.Compare(v.Id, filter.After.Id)
+	}
+	if filter.Descending {
+		return c < 0
+	}
+	return c > 0
+}
+
+func (r *MockVideoRepository) FindExpired(ctx context.Context, ownerId string, exceptOwnerIds []string, before time.Time, limit int) ([]entity.VideoFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []entity.VideoFile
+	for _, v := range r.videos {
+		finished, ok := r.finishedAt[v.Id]
+		if !ok || !finished.Before(before) || v.Status == "processing" || v.Status == "expired" {
+			continue
+		}
+		if (ownerId != "" && v.OwnerId != ownerId) || slices.Contains(exceptOwnerIds, v.OwnerId) || len(result) >= limit {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (r *MockVideoRepository) Delete(ctx context.Context, videoId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range r.videos {
+		if v.Id == videoId {
+			r.videos = append(r.videos[:i], r.videos[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *MockVideoRepository) FindById(ctx context.Context, videoId string) (*entity.VideoFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.find(videoId)
+}
+
+func (r *MockVideoRepository) find(videoId string) (*entity.VideoFile, error) {
 	for _, v := range r.videos {
 		if v.Id == videoId {
 			return &v, nil
 		}
 	}
-	return nil, fmt.Errorf("video not found")
+	return nil, entity.ErrVideoNotFound
+}
+
+func (r *MockVideoRepository) status(videoId string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	video, _ := r.find(videoId)
+	return video.Status
 }
 
 type MockZipRepository struct {
 	files map[string]bytes.Buffer
 }
 
-func (r *MockZipRepository) UploadFile(filename string, file io.Reader) error {
+func (r *MockZipRepository) UploadFile(ctx context.Context, filename string, file io.Reader) error {
 	var buf bytes.Buffer
 	_, err := buf.ReadFrom(file)
 	if err != nil {
@@ -62,13 +283,18 @@ func (r *MockZipRepository) UploadFile(filename string, file io.Reader) error {
 	return nil
 }
 
-func (r *MockZipRepository) DownloadFile(filename string) (io.Reader, error) {
+func (r *MockZipRepository) DownloadFile(ctx context.Context, filename string) (io.Reader, error) {
 	if _, exists := r.files[filename]; exists {
 		// file := &mockMultipartFile{Reader: bytes.NewReader(fileContent)}
 	}
 	return nil, fmt.Errorf("file not found")
 }
 
+func (r *MockZipRepository) Delete(ctx context.Context, filename string) error {
+	delete(r.files, filename)
+	return nil
+}
+
 type mockMultipartFile struct {
 	*bytes.Reader
 }
@@ -98,7 +324,7 @@ func TestGenerateFrames_Success(t *testing.T) {
 	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
 
 	// Call GenerateFrames (should not create actual files)
-	err := videoUseCase.GenerateFrames(file, header, ownerId)
+	_, err := videoUseCase.GenerateFrames(context.Background(), file, header, ownerId)
 	if err == nil {
 		t.Errorf("Expected error, got %v", err)
 	}
@@ -125,7 +351,7 @@ func TestGenerateFrames_ErrorHandling(t *testing.T) {
 	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
 
 	// Call GenerateFrames (should return an error)
-	err := videoUseCase.GenerateFrames(file, header, ownerId)
+	_, err := videoUseCase.GenerateFrames(context.Background(), file, header, ownerId)
 	if err == nil {
 		t.Error("Expected error for invalid file extension, but got nil")
 	}
@@ -144,14 +370,83 @@ func TestGetVideos(t *testing.T) {
 	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
 
 	// Call GetVideos
-	videos, err := videoUseCase.GetVideos("123")
+	page, err := videoUseCase.GetVideos(context.Background(), "123", entity.VideoFilter{})
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
 
 	// Validate response
-	if len(videos) != 2 {
-		t.Errorf("Expected 2 videos, got %d", len(videos))
+	if len(page.Videos) != 2 || page.Total != 2 || page.NextCursor != "" {
+		t.Errorf("Expected a single page of 2 videos, got %+v", page)
+	}
+}
+
+func TestGetVideos_PagesFiltersAndSorts(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	finished := func(hours int) *time.Time {
+		at := start.Add(time.Duration(hours) * time.Hour)
+		return &at
+	}
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "a", Status: "ready_to_download", CreatedAt: start, FinishedAt: finished(10)},
+			{OwnerId: "123", Id: "b", Status: "error", CreatedAt: start.Add(time.Hour), FinishedAt: finished(2)},
+			{OwnerId: "123", Id: "c", Status: "ready_to_download", CreatedAt: start.Add(2 * time.Hour), FinishedAt: finished(5)},
+			{OwnerId: "123", Id: "d", Status: "processing", CreatedAt: start.Add(3 * time.Hour)},
+			{OwnerId: "123", Id: "e", Status: "ready_to_download", CreatedAt: start.Add(4 * time.Hour), FinishedAt: finished(6)},
+			{OwnerId: "456", Id: "f", Status: "ready_to_download", CreatedAt: start.Add(5 * time.Hour)},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	list := func(filter entity.VideoFilter) []string {
+		t.Helper()
+		var ids []string
+		for {
+			page, err := videoUseCase.GetVideos(context.Background(), "123", filter)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(page.Videos) > filter.Limit {
+				t.Fatalf("Expected at most %d videos per page, got %d", filter.Limit, len(page.Videos))
+			}
+			for _, video := range page.Videos {
+				ids = append(ids, video.Id)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			filter.After, err = entity.ParseVideoCursor(page.NextCursor, entity.VideoFilter{Sort: filter.Sort, Descending: filter.Descending})
+			if err != nil {
+				t.Fatalf("Expected a valid cursor, got %v", err)
+			}
+		}
+	}
+
+	cases := []struct {
+		name     string
+		filter   entity.VideoFilter
+		expected []string
+	}{
+		{"newest first", entity.VideoFilter{Sort: entity.SortCreatedAt, Descending: true, Limit: 2}, []string{"e", "d", "c", "b", "a"}},
+		{"by finish time", entity.VideoFilter{Sort: entity.SortFinishedAt, Limit: 2}, []string{"b", "c", "e", "a", "d"}},
+		{"by status", entity.VideoFilter{Sort: entity.SortCreatedAt, Status: "ready_to_download", Limit: 1}, []string{"a", "c", "e"}},
+		{"by creation range", entity.VideoFilter{Sort: entity.SortCreatedAt, CreatedAfter: start.Add(time.Hour), CreatedBefore: start.Add(4 * time.Hour), Limit: 2}, []string{"b", "c", "d"}},
+	}
+	for _, c := range cases {
+		if ids := list(c.filter); !slices.Equal(ids, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, ids)
+		}
+	}
+
+	page, _ := videoUseCase.GetVideos(context.Background(), "123", entity.VideoFilter{Status: "ready_to_download", Limit: 1})
+	if page.Total != 3 {
+		t.Errorf("Expected the total to count every match, got %d", page.Total)
+	}
+
+	_, err := entity.ParseVideoCursor(page.NextCursor, entity.VideoFilter{Sort: entity.SortFinishedAt})
+	if !errors.Is(err, entity.ErrInvalidParameters) {
+		t.Errorf("Expected cursors to be tied to their sort order, got %v", err)
 	}
 }
 
@@ -171,8 +466,814 @@ func TestDownloadZip_Success(t *testing.T) {
 	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
 
 	// Call DownloadZip
-	_, err := videoUseCase.DownloadZip("video1", "123")
+	_, err := videoUseCase.DownloadZip(context.Background(), "video1", "123", 0)
 	if err == nil {
 		t.Errorf("Expected file not found, got %v", err)
 	}
 }
+
+type MockJobSignaler struct {
+	cancelled []string
+}
+
+func (s *MockJobSignaler) SignalCancel(videoId string) error {
+	s.cancelled = append(s.cancelled, videoId)
+	return nil
+}
+
+func TestCancel_Processing(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "processing"},
+		},
+	}
+	signaler := &MockJobSignaler{}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.Signaler = signaler
+
+	ctx := videoUseCase.startJob(context.Background(), "video1")
+
+	video, err := videoUseCase.Cancel(context.Background(), "video1", "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if video.Status != "cancelled" || videoRepo.status("video1") != "cancelled" {
+		t.Errorf("Expected video to be cancelled, got %s", videoRepo.status("video1"))
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the local job context to be cancelled")
+	}
+	if len(signaler.cancelled) != 1 || signaler.cancelled[0] != "video1" {
+		t.Errorf("Expected cancellation to be signaled, got %v", signaler.cancelled)
+	}
+	if len(videoRepo.events) != 1 || videoRepo.events[0].Event != entity.EventVideoCancelled {
+		t.Errorf("Expected a cancelled event, got %+v", videoRepo.events)
+	}
+}
+
+func TestCancel_NotProcessing(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "ready_to_download"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	_, err := videoUseCase.Cancel(context.Background(), "video1", "123")
+	if !errors.Is(err, entity.ErrStatusConflict) {
+		t.Errorf("Expected status conflict, got %v", err)
+	}
+}
+
+func TestCancel_WrongOwner(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "processing"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	_, err := videoUseCase.Cancel(context.Background(), "video1", "456")
+	if !errors.Is(err, entity.ErrVideoNotFound) {
+		t.Errorf("Expected video not found, got %v", err)
+	}
+}
+
+func TestDelete_RemovesEverything(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "processing", SourceKey: "sources/video1.mp4"},
+			{OwnerId: "123", Id: "video2", Status: "ready_to_download"},
+		},
+		archives: []entity.VideoArchive{{VideoId: "video1", Version: 1, Key: "video1.zip"}},
+	}
+	zipRepo := &MockZipRepository{files: make(map[string]bytes.Buffer)}
+	for _, key := range []string{"video1.zip", "sources/video1.mp4", "video2.zip"} {
+		zipRepo.files[key] = bytes.Buffer{}
+	}
+	signaler := &MockJobSignaler{}
+	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
+	videoUseCase.Signaler = signaler
+
+	ctx := videoUseCase.startJob(context.Background(), "video1")
+
+	err := videoUseCase.Delete(context.Background(), "video1", "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(videoRepo.videos) != 1 || videoRepo.videos[0].Id != "video2" {
+		t.Errorf("Expected only the other video to remain, got %+v", videoRepo.videos)
+	}
+	if _, kept := zipRepo.files["video2.zip"]; len(zipRepo.files) != 1 || !kept {
+		t.Errorf("Expected only the other video's files to remain, got %v", zipRepo.files)
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the running job to be cancelled")
+	}
+	if len(signaler.cancelled) != 1 || signaler.cancelled[0] != "video1" {
+		t.Errorf("Expected cancellation to be signaled, got %v", signaler.cancelled)
+	}
+	if len(videoRepo.events) != 1 || videoRepo.events[0].Event != entity.EventVideoCancelled {
+		t.Errorf("Expected the video to be cancelled before deletion, got %+v", videoRepo.events)
+	}
+
+	err = videoUseCase.Delete(context.Background(), "video1", "123")
+	if err != nil {
+		t.Errorf("Expected deleting a deleted video to succeed, got %v", err)
+	}
+}
+
+func TestDelete_JobCompletedMeanwhile(t *testing.T) {
+	// The job recorded its archive after the video was read as processing.
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "ready_to_download", SourceKey: "sources/video1.mp4"},
+		},
+		archives: []entity.VideoArchive{{VideoId: "video1", Version: 1, Key: "video1.zip", Size: 10}},
+	}
+	zipRepo := &MockZipRepository{files: make(map[string]bytes.Buffer)}
+	for _, key := range []string{"video1.zip", "sources/video1.mp4"} {
+		zipRepo.files[key] = bytes.Buffer{}
+	}
+	usage := &MockUsageRepository{usage: map[string]entity.Usage{"123": {OwnerId: "123", Videos: 1, ArchiveBytes: 10}}}
+	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
+	videoUseCase.Usage = usage
+
+	err := videoUseCase.purge(context.Background(), entity.VideoFile{OwnerId: "123", Id: "video1", Status: "processing", SourceKey: "sources/video1.mp4"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(videoRepo.videos) != 0 || len(zipRepo.files) != 0 {
+		t.Errorf("Expected the completed archive to be deleted too, got %+v %v", videoRepo.videos, zipRepo.files)
+	}
+	if got := usage.usage["123"]; got.Videos != 0 || got.ArchiveBytes != 0 {
+		t.Errorf("Expected the archive's usage to be released, got %+v", got)
+	}
+}
+
+func TestDelete_WrongOwner(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "ready_to_download"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	err := videoUseCase.Delete(context.Background(), "video1", "456")
+	if !errors.Is(err, entity.ErrVideoNotFound) {
+		t.Errorf("Expected video not found, got %v", err)
+	}
+	if len(videoRepo.videos) != 1 {
+		t.Errorf("Expected the video to be kept, got %+v", videoRepo.videos)
+	}
+}
+
+func TestReprocess_StartsNewVersion(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "ready_to_download", SourceKey: "sources/video1.mp4"},
+		},
+		archives: []entity.VideoArchive{
+			{VideoId: "video1", Version: 1, Key: "video1.zip"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.MaxAttempts = 1
+
+	video, err := videoUseCase.Reprocess(context.Background(), "video1", "123", 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if video.Status != "processing" {
+		t.Errorf("Expected processing status, got %s", video.Status)
+	}
+
+	videoRepo.mu.Lock()
+	event := videoRepo.events[0]
+	videoRepo.mu.Unlock()
+	if event.Event != entity.EventVideoReprocessing || event.Version != 2 {
+		t.Errorf("Expected reprocessing event for version 2, got %+v", event)
+	}
+
+	// The source can't be restored from the mock storage, so the job
+	// exhausts its single attempt.
+	deadline := time.Now().Add(time.Second)
+	for videoRepo.status("video1") != "dead_letter" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if videoRepo.status("video1") != "dead_letter" {
+		t.Errorf("Expected failed reprocessing to end in dead_letter, got %s", videoRepo.status("video1"))
+	}
+}
+
+func TestReprocess_Errors(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "processing", Status: "processing", SourceKey: "sources/processing.mp4"},
+			{OwnerId: "123", Id: "discarded", Status: "error"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	cases := []struct {
+		videoId  string
+		ownerId  string
+		interval int
+		expected error
+	}{
+		{"processing", "456", 0, entity.ErrVideoNotFound},
+		{"processing", "123", -1, entity.ErrInvalidParameters},
+		{"processing", "123", 0, entity.ErrStatusConflict},
+		{"discarded", "123", 0, entity.ErrSourceUnavailable},
+	}
+	for _, c := range cases {
+		_, err := videoUseCase.Reprocess(context.Background(), c.videoId, c.ownerId, c.interval)
+		if !errors.Is(err, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.videoId, c.expected, err)
+		}
+	}
+}
+
+func TestMaxJobsPerOwner(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "busy1", Status: "processing"},
+			{OwnerId: "123", Id: "busy2", Status: "processing"},
+			{OwnerId: "123", Id: "done", Status: "ready_to_download", SourceKey: "sources/done.mp4"},
+			{OwnerId: "456", Id: "other", Status: "ready_to_download", SourceKey: "sources/other.mp4"},
+		},
+	}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.MaxJobsPerOwner = 2
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error { return nil }
+
+	file := &mockMultipartFile{Reader: bytes.NewReader([]byte("dummy video content"))}
+	_, err := videoUseCase.GenerateFrames(context.Background(), file, &multipart.FileHeader{Filename: "video.mp4"}, "123")
+	if !errors.Is(err, entity.ErrTooManyJobs) {
+		t.Errorf("Expected the upload to be refused, got %v", err)
+	}
+
+	_, err = videoUseCase.Reprocess(context.Background(), "done", "123", 0)
+	if !errors.Is(err, entity.ErrTooManyJobs) {
+		t.Errorf("Expected the reprocess to be refused, got %v", err)
+	}
+
+	_, err = videoUseCase.Reprocess(context.Background(), "other", "456", 0)
+	if err != nil {
+		t.Errorf("Expected other owners to be unaffected, got %v", err)
+	}
+}
+
+type MockUsageRepository struct {
+	mu    sync.Mutex
+	usage map[string]entity.Usage
+}
+
+func (m *MockUsageRepository) Add(ctx context.Context, delta entity.Usage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := m.usage[delta.OwnerId]
+	usage.OwnerId = delta.OwnerId
+	usage.Videos += delta.Videos
+	usage.SourceBytes += delta.SourceBytes
+	usage.ArchiveBytes += delta.ArchiveBytes
+	usage.ProcessingSeconds += delta.ProcessingSeconds
+	m.usage[delta.OwnerId] = usage
+	return nil
+}
+
+func (m *MockUsageRepository) Reserve(ctx context.Context, delta entity.Usage, quota entity.Quota) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := m.usage[delta.OwnerId]
+	err := quota.Check(usage, delta.Videos, delta.SourceBytes+delta.ArchiveBytes)
+	if err != nil {
+		return err
+	}
+	usage.OwnerId = delta.OwnerId
+	usage.Videos += delta.Videos
+	usage.SourceBytes += delta.SourceBytes
+	usage.ArchiveBytes += delta.ArchiveBytes
+	usage.ProcessingSeconds += delta.ProcessingSeconds
+	m.usage[delta.OwnerId] = usage
+	return nil
+}
+
+func (m *MockUsageRepository) Get(ctx context.Context, ownerId string) (*entity.Usage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := m.usage[ownerId]
+	usage.OwnerId = ownerId
+	return &usage, nil
+}
+
+func TestQuota_EnforcedOnNewWork(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{{OwnerId: "123", Id: "done", Status: "ready_to_download", SourceKey: "sources/done.mp4"}},
+	}
+	usage := &MockUsageRepository{usage: map[string]entity.Usage{
+		"123": {Videos: 1, SourceBytes: 600, ArchiveBytes: 300, ProcessingSeconds: 50},
+	}}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.Usage = usage
+	videoUseCase.RetainSource = true
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error { return nil }
+
+	cases := []struct {
+		quota    entity.Quota
+		size     int64
+		expected string
+	}{
+		{entity.Quota{MaxVideos: 1}, 10, "videos"},
+		{entity.Quota{MaxStorageBytes: 1000}, 200, "storage"},
+		{entity.Quota{MaxProcessingSeconds: 50}, 10, "processing time"},
+	}
+	for _, c := range cases {
+		videoUseCase.Quota = c.quota
+		file := &mockMultipartFile{Reader: bytes.NewReader([]byte("dummy video content"))}
+		_, err := videoUseCase.GenerateFrames(context.Background(), file, &multipart.FileHeader{Filename: "video.mp4", Size: c.size}, "123")
+		if !errors.Is(err, entity.ErrQuotaExceeded) || !strings.HasSuffix(err.Error(), c.expected) {
+			t.Errorf("%+v: expected the %s quota to be enforced, got %v", c.quota, c.expected, err)
+		}
+	}
+
+	// Reprocessing adds no video and its archive size is unknown up front.
+	videoUseCase.Quota = entity.Quota{MaxVideos: 1, MaxStorageBytes: 1000}
+	_, err := videoUseCase.Reprocess(context.Background(), "done", "123", 0)
+	if err != nil {
+		t.Errorf("Expected reprocessing within quota to be accepted, got %v", err)
+	}
+}
+
+type failingUploadRepository struct {
+	*MockZipRepository
+}
+
+func (failingUploadRepository) UploadFile(ctx context.Context, filename string, file io.Reader) error {
+	return errors.New("storage unavailable")
+}
+
+func TestQuota_ReservedUpFront(t *testing.T) {
+	usage := &MockUsageRepository{usage: map[string]entity.Usage{}}
+	zipRepo := &MockZipRepository{files: make(map[string]bytes.Buffer)}
+	videoUseCase := NewVideoUseCase(&MockVideoRepository{}, failingUploadRepository{zipRepo})
+	videoUseCase.Usage = usage
+	videoUseCase.Quota = entity.Quota{MaxVideos: 1}
+	videoUseCase.RetainSource = true
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error { return nil }
+
+	// An mp4 header, so the upload passes the MIME check.
+	content := []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom")
+	upload := func() error {
+		file := &mockMultipartFile{Reader: bytes.NewReader(content)}
+		_, err := videoUseCase.GenerateFrames(context.Background(), file, &multipart.FileHeader{Filename: "video.mp4", Size: 10}, "123")
+		return err
+	}
+
+	err := upload()
+	if err == nil || usage.usage["123"].Videos != 0 || usage.usage["123"].SourceBytes != 0 {
+		t.Fatalf("Expected the failed upload's reservation to be released, got %v %+v", err, usage.usage["123"])
+	}
+
+	videoUseCase.ZipRepository = zipRepo
+	err = upload()
+	if err != nil {
+		t.Fatalf("Expected the upload to be accepted, got %v", err)
+	}
+	if usage.usage["123"].Videos != 1 || usage.usage["123"].SourceBytes != int64(len(content)) {
+		t.Errorf("Expected the reservation to be settled with the stored size, got %+v", usage.usage["123"])
+	}
+
+	err = upload()
+	if !errors.Is(err, entity.ErrQuotaExceeded) {
+		t.Errorf("Expected the reserved video to count against the quota, got %v", err)
+	}
+}
+
+func TestUsage_RecordedByPipeline(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "processing", SourceKey: "sources/video1.mp4", SourceSize: 600}},
+		archives: []entity.VideoArchive{
+			{VideoId: "video1", Version: 1, Key: "video1.zip", Size: 200},
+			{VideoId: "video1", Version: 2, Key: "video1_v2.zip", Size: 100},
+		},
+	}
+	usage := &MockUsageRepository{usage: map[string]entity.Usage{
+		"123": {Videos: 1, SourceBytes: 600, ArchiveBytes: 300},
+	}}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.Usage = usage
+	videoUseCase.Quota = entity.Quota{MaxVideos: 10}
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	video := videoRepo.videos[0]
+	videoUseCase.process(videoUseCase.startJob(context.Background(), video.Id), &video)
+
+	response, _ := videoUseCase.GetUsage(context.Background(), "123")
+	if response.ProcessingSeconds < 0.01 || response.StorageBytes != 900 || response.Limits.MaxVideos != 10 {
+		t.Errorf("Expected processing time to be recorded against the limits, got %+v", response)
+	}
+
+	err := videoUseCase.purge(context.Background(), video)
+	if err != nil {
+		t.Fatalf("Expected video to be purged, got %v", err)
+	}
+
+	remaining := usage.usage["123"]
+	if remaining.Videos != 0 || remaining.StorageBytes() != 0 {
+		t.Errorf("Expected purging to release the video's storage, got %+v", remaining)
+	}
+}
+
+func TestDownloadZip_Version(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "video1", Status: "processing"},
+		},
+		archives: []entity.VideoArchive{
+			{VideoId: "video1", Version: 1, Key: "video1.zip"},
+			{VideoId: "video1", Version: 2, Key: "video1_v2.zip"},
+		},
+	}
+	zipRepo := &MockZipRepository{files: make(map[string]bytes.Buffer)}
+	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
+
+	_, err := videoUseCase.DownloadZip(context.Background(), "video1", "123", 3)
+	if !errors.Is(err, entity.ErrVideoNotReady) {
+		t.Errorf("Expected missing version error, got %v", err)
+	}
+}
+
+func newRetryingUseCase(videoRepo *MockVideoRepository, failures []error) (*VideoUseCase, *int) {
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.MaxAttempts = 3
+	videoUseCase.RetryBaseDelay = time.Millisecond
+	videoUseCase.RetryMaxDelay = 5 * time.Millisecond
+
+	runs := 0
+	videoUseCase.runJob = func(ctx context.Context, videoFile *entity.VideoFile) error {
+		runs++
+		if runs <= len(failures) {
+			return failures[runs-1]
+		}
+		videoFile.Status = "ready_to_download"
+		return videoRepo.CompleteProcessing(ctx, entity.NewVideoArchive(*videoFile), entity.NewVideoEvent(*videoFile, entity.EventVideoReady))
+	}
+
+	return videoUseCase, &runs
+}
+
+func TestProcess_RetriesTransientFailures(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "processing"}},
+	}
+	videoUseCase, runs := newRetryingUseCase(videoRepo, []error{fmt.Errorf("s3 timeout")})
+
+	err := videoUseCase.process(context.Background(), &entity.VideoFile{OwnerId: "123", Id: "video1", Status: "processing", Version: 1})
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+
+	if *runs != 2 || videoRepo.status("video1") != "ready_to_download" {
+		t.Errorf("Expected 2 runs and ready status, got %d %s", *runs, videoRepo.status("video1"))
+	}
+	if videoRepo.videos[0].Attempts != 2 || videoRepo.videos[0].LastError != "s3 timeout" {
+		t.Errorf("Expected attempt count and last error to be recorded, got %+v", videoRepo.videos[0])
+	}
+}
+
+func TestProcess_PermanentFailureIsNotRetried(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "processing"}},
+	}
+	videoUseCase, runs := newRetryingUseCase(videoRepo, []error{entity.Permanent(fmt.Errorf("corrupt input"))})
+
+	err := videoUseCase.process(context.Background(), &entity.VideoFile{OwnerId: "123", Id: "video1", Status: "processing", Version: 1})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if *runs != 1 || videoRepo.status("video1") != "error" {
+		t.Errorf("Expected a single run and error status, got %d %s", *runs, videoRepo.status("video1"))
+	}
+}
+
+func TestProcess_DeadLettersExhaustedJobs(t *testing.T) {
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "processing"}},
+	}
+	failure := fmt.Errorf("db hiccup")
+	videoUseCase, runs := newRetryingUseCase(videoRepo, []error{failure, failure, failure})
+
+	err := videoUseCase.process(context.Background(), &entity.VideoFile{OwnerId: "123", Id: "video1", Status: "processing", Version: 1})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected last failure, got %v", err)
+	}
+
+	if *runs != 3 || videoRepo.status("video1") != "dead_letter" {
+		t.Errorf("Expected 3 runs and dead_letter status, got %d %s", *runs, videoRepo.status("video1"))
+	}
+	if len(videoRepo.events) != 1 || videoRepo.events[0].Event != entity.EventVideoDeadLettered {
+		t.Errorf("Expected a dead letter event, got %+v", videoRepo.events)
+	}
+}
+
+func TestBackoff_StaysWithinBounds(t *testing.T) {
+	for attempt := 1; attempt <= 40; attempt++ {
+		delay := backoff(attempt, time.Second, 10*time.Second)
+		if delay < 0 || delay > 10*time.Second {
+			t.Errorf("attempt %d: delay %s out of bounds", attempt, delay)
+		}
+	}
+
+	// Large bases overflow the shift long before attempt 32; late attempts
+	// must still draw from the whole capped range.
+	for attempt := 10; attempt <= 40; attempt++ {
+		longest := time.Duration(0)
+		for i := 0; i < 20; i++ {
+			delay := backoff(attempt, time.Hour, 24*time.Hour)
+			if delay < 0 || delay > 24*time.Hour {
+				t.Fatalf("attempt %d: delay %s out of bounds with a large base", attempt, delay)
+			}
+			longest = max(longest, delay)
+		}
+		if longest < 12*time.Hour {
+			t.Errorf("attempt %d: expected delays up to the cap, longest was %s", attempt, longest)
+		}
+	}
+}
+
+func TestGenerateFrames_RejectedWhileDraining(t *testing.T) {
+	videoUseCase := NewVideoUseCase(&MockVideoRepository{}, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	err := videoUseCase.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("Expected idle drain to succeed, got %v", err)
+	}
+
+	_, err = videoUseCase.GenerateFrames(context.Background(), nil, nil, "123")
+	if !errors.Is(err, entity.ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+	_, err = videoUseCase.Reprocess(context.Background(), "video1", "123", 0)
+	if !errors.Is(err, entity.ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+}
+
+func TestDrain_WaitsForRunningJobs(t *testing.T) {
+	videoRepo := &MockVideoRepository{}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+
+	videoUseCase.startJob(context.Background(), "video1")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		videoUseCase.finishJob("video1")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := videoUseCase.Drain(ctx)
+	if err != nil {
+		t.Fatalf("Expected drain to succeed, got %v", err)
+	}
+	if len(videoRepo.released) != 0 {
+		t.Errorf("Expected no jobs to be released, got %v", videoRepo.released)
+	}
+}
+
+func TestDrain_ReleasesJobsAtDeadline(t *testing.T) {
+	videoRepo := &MockVideoRepository{}
+	videoUseCase := NewVideoUseCase(videoRepo, &MockZipRepository{files: make(map[string]bytes.Buffer)})
+	videoUseCase.WorkerId = "worker-1"
+
+	jobCtx := videoUseCase.startJob(context.Background(), "video1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := videoUseCase.Drain(ctx)
+	if err != nil {
+		t.Fatalf("Expected drain to succeed, got %v", err)
+	}
+
+	if jobCtx.Err() == nil {
+		t.Error("Expected running job to be cancelled")
+	}
+	if len(videoRepo.released) != 1 || videoRepo.released[0] != "worker-1" {
+		t.Errorf("Expected jobs of worker-1 to be released, got %v", videoRepo.released)
+	}
+}
+
+type MockVideoMetrics struct {
+	nopMetrics
+	mu        sync.Mutex
+	successes int
+	failures  []string
+}
+
+func (m *MockVideoMetrics) JobSucceeded(frameCount int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.successes++
+}
+
+func (m *MockVideoMetrics) JobFailed(errorClass string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures = append(m.failures, errorClass)
+}
+
+func TestProcess_RecordsMetricsByErrorClass(t *testing.T) {
+	failure := fmt.Errorf("db hiccup")
+	cases := map[string]struct {
+		failures  []error
+		successes int
+		classes   []string
+	}{
+		"retried":     {[]error{failure}, 1, []string{ErrorClassTransient}},
+		"permanent":   {[]error{entity.Permanent(failure)}, 0, []string{ErrorClassPermanent}},
+		"dead letter": {[]error{failure, failure, failure}, 0, []string{ErrorClassTransient, ErrorClassTransient, ErrorClassExhausted}},
+	}
+	for name, c := range cases {
+		videoRepo := &MockVideoRepository{
+			videos: []entity.VideoFile{{OwnerId: "123", Id: "video1", Status: "processing"}},
+		}
+		videoUseCase, _ := newRetryingUseCase(videoRepo, c.failures)
+		videoMetrics := &MockVideoMetrics{}
+		videoUseCase.Metrics = videoMetrics
+
+		videoUseCase.process(context.Background(), &entity.VideoFile{OwnerId: "123", Id: "video1", Status: "processing", Version: 1})
+
+		if videoMetrics.successes != c.successes || fmt.Sprint(videoMetrics.failures) != fmt.Sprint(c.classes) {
+			t.Errorf("%s: expected %d successes and failures %v, got %d %v", name, c.successes, c.classes, videoMetrics.successes, videoMetrics.failures)
+		}
+	}
+}
+
+// slowZipRepository blocks every call until its context is done, like a
+// storage backend that stopped responding.
+type slowZipRepository struct{}
+
+func (slowZipRepository) UploadFile(ctx context.Context, key string, file io.Reader) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (slowZipRepository) DownloadFile(ctx context.Context, key string) (io.Reader, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowZipRepository) Delete(ctx context.Context, key string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStorageTimeouts_BoundStuckCalls(t *testing.T) {
+	videoUseCase := NewVideoUseCase(&MockVideoRepository{}, slowZipRepository{})
+	videoUseCase.UploadTimeout = 10 * time.Millisecond
+	videoUseCase.DownloadTimeout = 10 * time.Millisecond
+
+	err := videoUseCase.uploadFile(context.Background(), "video1.zip", bytes.NewReader(nil))
+	if !errors.Is(err, context.DeadlineExceeded) || !entity.IsTransient(err) {
+		t.Errorf("Expected a transient deadline error on upload, got %v", err)
+	}
+
+	_, err = videoUseCase.downloadFile(context.Background(), "video1.zip")
+	if !errors.Is(err, context.DeadlineExceeded) || !entity.IsTransient(err) {
+		t.Errorf("Expected a transient deadline error on download, got %v", err)
+	}
+}
+
+func TestGenerateVideoFrames_ReportsTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := GenerateVideoFrames(ctx, "missing.mp4", "frame_missing_%04d.png", 1)
+	if !errors.Is(err, context.DeadlineExceeded) || !entity.IsTransient(err) {
+		t.Errorf("Expected a transient deadline error, got %v", err)
+	}
+}
+
+// deletingZipRepository records the keys deleted from it.
+type deletingZipRepository struct {
+	archiveZipRepository
+	mu      sync.Mutex
+	deleted []string
+	// failing holds keys whose deletion fails.
+	failing map[string]bool
+}
+
+func (r *deletingZipRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failing[key] {
+		return errors.New("storage unavailable")
+	}
+	r.deleted = append(r.deleted, key)
+	return nil
+}
+
+func TestExpire_AppliesRetentionPolicy(t *testing.T) {
+	now := time.Now()
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "old", Status: "ready_to_download", SourceKey: "sources/old.mp4", SourceSize: 600},
+			{OwnerId: "123", Id: "recent", Status: "ready_to_download"},
+			{OwnerId: "123", Id: "running", Status: "processing"},
+			{OwnerId: "456", Id: "kept", Status: "ready_to_download"},
+			{OwnerId: "789", Id: "short", Status: "error"},
+		},
+		archives: []entity.VideoArchive{{VideoId: "old", Version: 1, Key: "old.zip", Size: 300}},
+		finishedAt: map[string]time.Time{
+			"old":     now.Add(-48 * time.Hour),
+			"recent":  now.Add(-time.Hour),
+			"running": now.Add(-48 * time.Hour),
+			"kept":    now.Add(-48 * time.Hour),
+			"short":   now.Add(-2 * time.Hour),
+		},
+	}
+	zipRepo := &deletingZipRepository{}
+	usage := &MockUsageRepository{usage: map[string]entity.Usage{"123": {Videos: 3, SourceBytes: 600, ArchiveBytes: 300}}}
+	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
+	videoUseCase.Usage = usage
+	videoUseCase.Retention = entity.RetentionPolicy{
+		Default: 24 * time.Hour,
+		Owners:  map[string]time.Duration{"456": 0, "789": time.Hour},
+	}
+
+	expired, err := videoUseCase.Expire(context.Background())
+	if err != nil || expired != 2 {
+		t.Fatalf("Expected 2 videos to expire, got %d %v", expired, err)
+	}
+
+	statuses := map[string]string{}
+	for _, v := range videoRepo.videos {
+		statuses[v.Id] = v.Status
+	}
+	expected := map[string]string{"old": "expired", "recent": "ready_to_download", "running": "processing", "kept": "ready_to_download", "short": "expired"}
+	for id, status := range expected {
+		if statuses[id] != status {
+			t.Errorf("%s: expected status %s, got %s", id, status, statuses[id])
+		}
+	}
+
+	if !slices.Contains(zipRepo.deleted, "old.zip") || !slices.Contains(zipRepo.deleted, "sources/old.mp4") {
+		t.Errorf("Expected the archive and source to be deleted, got %v", zipRepo.deleted)
+	}
+	if remaining := usage.usage["123"]; remaining.StorageBytes() != 0 || remaining.Videos != 3 {
+		t.Errorf("Expected the storage to be released, got %+v", remaining)
+	}
+
+	_, err = videoUseCase.DownloadZip(context.Background(), "old", "123", 0)
+	if !errors.Is(err, entity.ErrVideoExpired) {
+		t.Errorf("Expected downloads of expired videos to fail with ErrVideoExpired, got %v", err)
+	}
+	_, err = videoUseCase.Reprocess(context.Background(), "old", "123", 0)
+	if !errors.Is(err, entity.ErrVideoExpired) {
+		t.Errorf("Expected expired videos not to be reprocessed, got %v", err)
+	}
+}
+
+func TestExpire_RetriesFailedDeletes(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	videoRepo := &MockVideoRepository{
+		videos: []entity.VideoFile{
+			{OwnerId: "123", Id: "stuck", Status: "ready_to_download", SourceKey: "sources/stuck.mp4", SourceSize: 600},
+			{OwnerId: "123", Id: "other", Status: "ready_to_download"},
+		},
+		finishedAt: map[string]time.Time{"stuck": old, "other": old},
+	}
+	zipRepo := &deletingZipRepository{failing: map[string]bool{"sources/stuck.mp4": true}}
+	usage := &MockUsageRepository{usage: map[string]entity.Usage{"123": {Videos: 2, SourceBytes: 600}}}
+	videoUseCase := NewVideoUseCase(videoRepo, zipRepo)
+	videoUseCase.Usage = usage
+	videoUseCase.Retention = entity.RetentionPolicy{Default: 24 * time.Hour}
+
+	expired, err := videoUseCase.Expire(context.Background())
+	if err == nil || expired != 1 {
+		t.Fatalf("Expected the other video to expire and the failure to be reported, got %d %v", expired, err)
+	}
+	if video, _ := videoRepo.FindById(context.Background(), "stuck"); video.Status != "ready_to_download" {
+		t.Errorf("Expected the video to stay unexpired, got %s", video.Status)
+	}
+	if usage.usage["123"].SourceBytes != 600 {
+		t.Errorf("Expected the storage to stay accounted, got %+v", usage.usage["123"])
+	}
+
+	zipRepo.failing = nil
+	expired, err = videoUseCase.Expire(context.Background())
+	if err != nil || expired != 1 {
+		t.Fatalf("Expected the next run to expire the video, got %d %v", expired, err)
+	}
+	if usage.usage["123"].SourceBytes != 0 {
+		t.Errorf("Expected the storage to be released, got %+v", usage.usage["123"])
+	}
+}
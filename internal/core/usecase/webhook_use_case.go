@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+	"github.com/gomesmatheus/tc-hackaton/internal/core/port"
+	"github.com/gomesmatheus/tc-hackaton/internal/logging"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	defaultWebhookAttempts = 5
+	defaultWebhookBackoff  = 30 * time.Second
+	webhookBatchSize       = 50
+	// webhookLease is how long a claimed delivery is held before another
+	// worker may retry it; it must outlast the sender's timeout.
+	webhookLease = time.Minute
+)
+
+// WebhookUseCase records a delivery per webhook for every event and sends
+// them from a background worker. Deliveries and their next attempt are
+// persisted, so retries survive restarts.
+type WebhookUseCase struct {
+	Repository  port.WebhookRepository
+	Sender      port.WebhookSender
+	MaxAttempts int
+	BaseBackoff time.Duration
+	Logger      *slog.Logger
+	wake        chan struct{}
+}
+
+func NewWebhookUseCase(repository port.WebhookRepository, sender port.WebhookSender) *WebhookUseCase {
+	return &WebhookUseCase{
+		Repository:  repository,
+		Sender:      sender,
+		MaxAttempts: defaultWebhookAttempts,
+		BaseBackoff: defaultWebhookBackoff,
+		Logger:      slog.Default(),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+func (w *WebhookUseCase) Register(ownerId string, url string) (*entity.WebhookResponse, error) {
+	webhook, err := entity.NewWebhook(ownerId, url)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Repository.Save(*webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	// The secret is only ever returned on creation.
+	response := getWebhookResponse(*webhook)
+	response.Secret = webhook.Secret
+
+	return &response, nil
+}
+
+func (w *WebhookUseCase) GetWebhooks(ownerId string) ([]entity.WebhookResponse, error) {
+	webhooks, err := w.Repository.FindByOwnerId(ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entity.WebhookResponse, 0)
+	for _, webhook := range webhooks {
+		response = append(response, getWebhookResponse(webhook))
+	}
+
+	return response, nil
+}
+
+func (w *WebhookUseCase) GetDeliveries(webhookId string, ownerId string) ([]entity.WebhookDeliveryResponse, error) {
+	webhook, err := w.Repository.FindById(webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook.OwnerId != ownerId {
+		return nil, entity.ErrWebhookNotFound
+	}
+
+	deliveries, err := w.Repository.FindDeliveriesByWebhookId(webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]entity.WebhookDeliveryResponse, 0)
+	for _, delivery := range deliveries {
+		response = append(response, getDeliveryResponse(delivery))
+	}
+
+	return response, nil
+}
+
+// Notify records a delivery of the event to every webhook registered by the
+// video owner, for the worker to send. A delivery's id is derived from the
+// event, so notifying the same event again records nothing new.
+func (w *WebhookUseCase) Notify(event entity.VideoEvent) error {
+	webhooks, err := w.Repository.FindByOwnerId(event.OwnerId)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		delivery := entity.NewWebhookDelivery(webhook.Id, event, payload)
+		err = w.Repository.SaveDelivery(*delivery)
+		if err != nil {
+			w.Logger.Error("Error saving webhook delivery", "webhook_id", webhook.Id, logging.VideoID, event.VideoId, "error", err)
+			return err
+		}
+	}
+
+	if len(webhooks) > 0 {
+		w.signal()
+	}
+	return nil
+}
+
+func (w *WebhookUseCase) Replay(deliveryId string, ownerId string) error {
+	delivery, err := w.Repository.FindDeliveryById(deliveryId)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := w.Repository.FindById(delivery.WebhookId)
+	if err != nil {
+		return err
+	}
+
+	if webhook.OwnerId != ownerId {
+		return entity.ErrDeliveryNotFound
+	}
+
+	now := time.Now().UTC()
+	delivery.Status = entity.DeliveryPending
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.NextAttemptAt = &now
+	delivery.UpdatedAt = now
+	err = w.Repository.UpdateDelivery(*delivery)
+	if err != nil {
+		return err
+	}
+
+	w.signal()
+	return nil
+}
+
+// RunDeliveries sends due deliveries every interval, or as soon as new ones
+// are recorded on this replica, until ctx is cancelled.
+func (w *WebhookUseCase) RunDeliveries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.DeliverDue(ctx)
+		if err != nil {
+			w.Logger.ErrorContext(ctx, "Error sending webhook deliveries", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// DeliverDue claims due deliveries and attempts each once, until none are
+// left, returning how many were delivered.
+func (w *WebhookUseCase) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for ctx.Err() == nil {
+		deliveries, err := w.Repository.ClaimDueDeliveries(webhookBatchSize, webhookLease)
+		if err != nil {
+			return delivered, err
+		}
+
+		for _, delivery := range deliveries {
+			webhook, err := w.Repository.FindById(delivery.WebhookId)
+			if errors.Is(err, entity.ErrWebhookNotFound) {
+				// Nothing left to send it to; give up instead of claiming
+				// it again on every run.
+				delivery.Status = entity.DeliveryFailed
+				delivery.LastError = "webhook was deleted"
+				delivery.NextAttemptAt = nil
+				delivery.UpdatedAt = time.Now().UTC()
+				err = w.Repository.UpdateDelivery(delivery)
+				if err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			if err != nil {
+				return delivered, err
+			}
+
+			if w.deliver(*webhook, &delivery) == nil {
+				delivered++
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			break
+		}
+	}
+
+	return delivered, nil
+}
+
+func (w *WebhookUseCase) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver makes a single attempt, then schedules the next one with
+// exponential backoff or gives up after MaxAttempts.
+func (w *WebhookUseCase) deliver(webhook entity.Webhook, delivery *entity.WebhookDelivery) error {
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		SignatureHeader: "sha256=" + Sign(webhook.Secret, delivery.Payload),
+		EventHeader:     delivery.Event,
+		DeliveryHeader:  delivery.Id,
+	}
+
+	code, err := w.Sender.Send(webhook.Url, delivery.Payload, headers)
+	if err == nil && (code < 200 || code >= 300) {
+		err = fmt.Errorf("unexpected status code %d", code)
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.UpdatedAt = now
+	if err == nil {
+		delivery.Status = entity.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		return w.Repository.UpdateDelivery(*delivery)
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= w.MaxAttempts {
+		delivery.Status = entity.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		w.Logger.Warn("Webhook delivery failed", "delivery_id", delivery.Id, "webhook_id", webhook.Id, "attempts", delivery.Attempts, "error", err)
+	} else {
+		next := now.Add(w.BaseBackoff * time.Duration(1<<min(delivery.Attempts-1, 16)))
+		delivery.NextAttemptAt = &next
+	}
+
+	updateErr := w.Repository.UpdateDelivery(*delivery)
+	if updateErr != nil {
+		return updateErr
+	}
+
+	return err
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload, which receivers
+// compare against the X-Webhook-Signature header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func getWebhookResponse(webhook entity.Webhook) entity.WebhookResponse {
+	return entity.WebhookResponse{
+		Id:        webhook.Id,
+		OwnerId:   webhook.OwnerId,
+		Url:       webhook.Url,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func getDeliveryResponse(delivery entity.WebhookDelivery) entity.WebhookDeliveryResponse {
+	return entity.WebhookDeliveryResponse{
+		Id:           delivery.Id,
+		WebhookId:    delivery.WebhookId,
+		VideoId:      delivery.VideoId,
+		Event:        delivery.Event,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		ResponseCode: delivery.ResponseCode,
+		LastError:    delivery.LastError,
+		CreatedAt:    delivery.CreatedAt,
+		UpdatedAt:    delivery.UpdatedAt,
+	}
+}
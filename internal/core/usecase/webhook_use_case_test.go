@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-hackaton/internal/core/entity"
+)
+
+type MockWebhookRepository struct {
+	mu         sync.Mutex
+	webhooks   []entity.Webhook
+	deliveries map[string]entity.WebhookDelivery
+}
+
+func (r *MockWebhookRepository) Save(webhook entity.Webhook) error {
+	r.webhooks = append(r.webhooks, webhook)
+	return nil
+}
+
+func (r *MockWebhookRepository) FindByOwnerId(ownerId string) ([]entity.Webhook, error) {
+	var result []entity.Webhook
+	for _, w := range r.webhooks {
+		if w.OwnerId == ownerId {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (r *MockWebhookRepository) FindById(id string) (*entity.Webhook, error) {
+	for _, w := range r.webhooks {
+		if w.Id == id {
+			return &w, nil
+		}
+	}
+	return nil, entity.ErrWebhookNotFound
+}
+
+func (r *MockWebhookRepository) SaveDelivery(delivery entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.deliveries[delivery.Id]; !exists {
+		r.deliveries[delivery.Id] = delivery
+	}
+	return nil
+}
+
+func (r *MockWebhookRepository) UpdateDelivery(delivery entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.Id] = delivery
+	return nil
+}
+
+func (r *MockWebhookRepository) FindDeliveryById(id string) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, exists := r.deliveries[id]; exists {
+		return &d, nil
+	}
+	return nil, entity.ErrDeliveryNotFound
+}
+
+func (r *MockWebhookRepository) FindDeliveriesByWebhookId(webhookId string) ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []entity.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.WebhookId == webhookId {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// ClaimDueDeliveries hands out every pending delivery regardless of when it
+// is due, so tests don't have to wait out the backoff.
+func (r *MockWebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []entity.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == entity.DeliveryPending && len(result) < limit {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+type MockWebhookSender struct {
+	mu        sync.Mutex
+	responses []int
+	calls     int
+	headers   map[string]string
+}
+
+func (s *MockWebhookSender) Send(url string, payload []byte, headers map[string]string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := s.responses[s.calls%len(s.responses)]
+	s.calls++
+	s.headers = headers
+	return code, nil
+}
+
+func newTestWebhookUseCase(sender *MockWebhookSender) (*WebhookUseCase, *MockWebhookRepository) {
+	repo := &MockWebhookRepository{deliveries: make(map[string]entity.WebhookDelivery)}
+	useCase := NewWebhookUseCase(repo, sender)
+	useCase.MaxAttempts = 3
+	useCase.BaseBackoff = time.Millisecond
+	return useCase, repo
+}
+
+func TestRegisterWebhook_InvalidUrl(t *testing.T) {
+	useCase, _ := newTestWebhookUseCase(&MockWebhookSender{responses: []int{200}})
+
+	_, err := useCase.Register("123", "ftp://example.com")
+	if err == nil {
+		t.Error("Expected error for invalid webhook url, got nil")
+	}
+}
+
+func TestRegisterWebhook_ReturnsSecretOnlyOnCreation(t *testing.T) {
+	useCase, _ := newTestWebhookUseCase(&MockWebhookSender{responses: []int{200}})
+
+	webhook, err := useCase.Register("123", "https://example.com/hook")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if webhook.Secret == "" {
+		t.Error("Expected secret to be returned on creation")
+	}
+
+	webhooks, err := useCase.GetWebhooks("123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].Secret != "" {
+		t.Errorf("Expected one webhook without secret, got %+v", webhooks)
+	}
+}
+
+func TestRegisterWebhook_RejectsPrivateAddresses(t *testing.T) {
+	useCase, _ := newTestWebhookUseCase(&MockWebhookSender{responses: []int{200}})
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data", "http://127.0.0.1:8080", "http://10.0.0.5/hook", "http://[::1]/hook", "http://localhost/hook"} {
+		_, err := useCase.Register("123", url)
+		if !errors.Is(err, entity.ErrInvalidWebhookURL) {
+			t.Errorf("%s: expected ErrInvalidWebhookURL, got %v", url, err)
+		}
+	}
+}
+
+func TestDeliverDue_RetriesAndSigns(t *testing.T) {
+	sender := &MockWebhookSender{responses: []int{500, 502, 204}}
+	useCase, repo := newTestWebhookUseCase(sender)
+	repo.webhooks = append(repo.webhooks, entity.Webhook{Id: "hook1", OwnerId: "123", Url: "https://example.com", Secret: "secret"})
+
+	err := useCase.Notify(entity.VideoEvent{Id: "event1", Event: entity.EventVideoReady, VideoId: "video1", OwnerId: "123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	delivered, _ := useCase.DeliverDue(context.Background())
+	if delivered != 0 || len(repo.deliveries) != 1 {
+		t.Fatalf("Expected the first attempt to fail, got %d delivered", delivered)
+	}
+	for _, delivery := range repo.deliveries {
+		if delivery.Status != entity.DeliveryPending || delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.After(time.Now()) {
+			t.Errorf("Expected the retry to be scheduled, got %+v", delivery)
+		}
+	}
+
+	useCase.DeliverDue(context.Background())
+	delivered, _ = useCase.DeliverDue(context.Background())
+	if delivered != 1 {
+		t.Fatalf("Expected the third attempt to succeed, got %d", delivered)
+	}
+	for _, delivery := range repo.deliveries {
+		if delivery.Attempts != 3 || delivery.Status != entity.DeliveryDelivered || delivery.NextAttemptAt != nil {
+			t.Errorf("Expected 3 attempts and delivered status, got %d %s", delivery.Attempts, delivery.Status)
+		}
+	}
+
+	var body []byte
+	for _, delivery := range repo.deliveries {
+		body = delivery.Payload
+	}
+	expected := "sha256=" + Sign("secret", body)
+	if sender.headers[SignatureHeader] != expected {
+		t.Errorf("Expected signature %s, got %s", expected, sender.headers[SignatureHeader])
+	}
+}
+
+func TestDeliverDue_ExhaustsAttempts(t *testing.T) {
+	sender := &MockWebhookSender{responses: []int{500}}
+	useCase, repo := newTestWebhookUseCase(sender)
+	repo.webhooks = append(repo.webhooks, entity.Webhook{Id: "hook1", OwnerId: "123", Url: "https://example.com", Secret: "secret"})
+	useCase.Notify(entity.VideoEvent{Id: "event1", Event: entity.EventVideoFailed, VideoId: "video1", OwnerId: "123"})
+
+	for i := 0; i < 5; i++ {
+		useCase.DeliverDue(context.Background())
+	}
+
+	if sender.calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", sender.calls)
+	}
+	for _, delivery := range repo.deliveries {
+		if delivery.Attempts != 3 || delivery.Status != entity.DeliveryFailed {
+			t.Errorf("Expected 3 attempts and failed status, got %d %s", delivery.Attempts, delivery.Status)
+		}
+	}
+}
+
+func TestDeliverDue_GivesUpOnDeletedWebhooks(t *testing.T) {
+	sender := &MockWebhookSender{responses: []int{200}}
+	useCase, repo := newTestWebhookUseCase(sender)
+	repo.webhooks = append(repo.webhooks, entity.Webhook{Id: "hook1", OwnerId: "123", Url: "https://example.com", Secret: "secret"})
+	useCase.Notify(entity.VideoEvent{Id: "event1", Event: entity.EventVideoReady, VideoId: "video1", OwnerId: "123"})
+	repo.webhooks = nil
+
+	useCase.DeliverDue(context.Background())
+
+	if sender.calls != 0 {
+		t.Errorf("Expected nothing to be sent, got %d calls", sender.calls)
+	}
+	for _, delivery := range repo.deliveries {
+		if delivery.Status != entity.DeliveryFailed || delivery.NextAttemptAt != nil {
+			t.Errorf("Expected the delivery to fail for good, got %s %v", delivery.Status, delivery.NextAttemptAt)
+		}
+	}
+	claimed, _ := repo.ClaimDueDeliveries(webhookBatchSize, webhookLease)
+	if len(claimed) != 0 {
+		t.Errorf("Expected the delivery not to be claimed again, got %+v", claimed)
+	}
+}
+
+func TestNotify_RecordsEachEventOnce(t *testing.T) {
+	useCase, repo := newTestWebhookUseCase(&MockWebhookSender{responses: []int{200}})
+	repo.webhooks = append(repo.webhooks, entity.Webhook{Id: "hook1", OwnerId: "123"}, entity.Webhook{Id: "hook2", OwnerId: "123"})
+	event := entity.VideoEvent{Id: "event1", Event: entity.EventVideoReady, VideoId: "video1", OwnerId: "123"}
+
+	useCase.Notify(event)
+	useCase.Notify(event)
+
+	if len(repo.deliveries) != 2 {
+		t.Errorf("Expected one delivery per webhook, got %d", len(repo.deliveries))
+	}
+}
+
+func TestReplay_WrongOwner(t *testing.T) {
+	useCase, repo := newTestWebhookUseCase(&MockWebhookSender{responses: []int{200}})
+	repo.webhooks = append(repo.webhooks, entity.Webhook{Id: "hook1", OwnerId: "123"})
+	repo.deliveries["delivery1"] = entity.WebhookDelivery{Id: "delivery1", WebhookId: "hook1"}
+
+	err := useCase.Replay("delivery1", "456")
+	if !errors.Is(err, entity.ErrDeliveryNotFound) {
+		t.Errorf("Expected ErrDeliveryNotFound replaying another owner's delivery, got %v", err)
+	}
+}
+
+func TestReplay_Redelivers(t *testing.T) {
+	sender := &MockWebhookSender{responses: []int{200}}
+	useCase, repo := newTestWebhookUseCase(sender)
+	repo.webhooks = append(repo.webhooks, entity.Webhook{Id: "hook1", OwnerId: "123", Url: "https://example.com"})
+	repo.deliveries["delivery1"] = entity.WebhookDelivery{Id: "delivery1", WebhookId: "hook1", Status: entity.DeliveryFailed, Attempts: 3}
+
+	err := useCase.Replay("delivery1", "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	delivered, _ := useCase.DeliverDue(context.Background())
+	if delivered != 1 || repo.deliveries["delivery1"].Status != entity.DeliveryDelivered {
+		t.Errorf("Expected delivery to be replayed, got %+v", repo.deliveries["delivery1"])
+	}
+}
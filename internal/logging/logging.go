@@ -0,0 +1,106 @@
+// Package logging configures the slog logger shared by every layer and
+// carries per-request and per-job attributes through context.Context.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Attribute keys used consistently across log lines.
+const (
+	RequestID = "request_id"
+	VideoID   = "video_id"
+	OwnerID   = "owner_id"
+	Attempt   = "attempt"
+	WorkerID  = "worker_id"
+)
+
+type attrsKey struct{}
+
+// New returns a logger writing JSON when format is "json" and text otherwise.
+// Attributes stored in the context with With are added to every record
+// logged through the *Context methods.
+func New(w io.Writer, format string, level string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	return slog.New(contextHandler{handler})
+}
+
+// With returns a copy of ctx whose log lines carry attrs in addition to the
+// ones already stored in it. Later values replace earlier ones with the same
+// key.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := fromContext(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	for _, attr := range existing {
+		if !hasKey(attrs, attr.Key) {
+			merged = append(merged, attr)
+		}
+	}
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// RequestIDFrom returns the request id stored in ctx, if any.
+func RequestIDFrom(ctx context.Context) string {
+	for _, attr := range fromContext(ctx) {
+		if attr.Key == RequestID {
+			return attr.Value.String()
+		}
+	}
+
+	return ""
+}
+
+func fromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseLevel(level string) slog.Level {
+	var parsed slog.Level
+	err := parsed.UnmarshalText([]byte(level))
+	if err != nil {
+		return slog.LevelInfo
+	}
+
+	return parsed
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	record.AddAttrs(fromContext(ctx)...)
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
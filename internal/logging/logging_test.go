@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNew_AddsContextAttributes(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, "json", "debug")
+
+	ctx := With(context.Background(), slog.String(RequestID, "req-1"), slog.String(VideoID, "video1"))
+	ctx = With(ctx, slog.Int(Attempt, 2), slog.String(VideoID, "video2"))
+	logger.InfoContext(ctx, "Processing video")
+
+	var line map[string]interface{}
+	err := json.Unmarshal(out.Bytes(), &line)
+	if err != nil {
+		t.Fatalf("Expected JSON output, got %q", out.String())
+	}
+
+	if line[RequestID] != "req-1" || line[VideoID] != "video2" || line[Attempt] != float64(2) {
+		t.Errorf("Expected context attributes on the line, got %v", line)
+	}
+	if RequestIDFrom(ctx) != "req-1" {
+		t.Errorf("Expected request id req-1, got %q", RequestIDFrom(ctx))
+	}
+}
+
+func TestNew_FiltersBelowLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, "text", "warn")
+
+	logger.Info("ignored")
+
+	if out.Len() != 0 {
+		t.Errorf("Expected info line to be filtered, got %q", out.String())
+	}
+}